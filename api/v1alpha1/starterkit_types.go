@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -31,20 +32,484 @@ type StarterKitSpec struct {
 
 	Options      StarterKitSpecOptions  `json:"options,omitempty"`
 	TemplateRepo StarterKitSpecTemplate `json:"templateRepo"`
+	// TemplateRef is the name of the StarterKitTemplate the StarterKit is created from. The template provides the
+	// template repo and the defaults of the options that are not set on the StarterKit.
+	// +optional
+	TemplateRef string `json:"templateRef,omitempty"`
+	// +optional
+	Route StarterKitSpecRoute `json:"route,omitempty"`
+	// Runtime selects how the application is deployed. Defaults to an always-on DeploymentConfig exposed
+	// through a Service and Route.
+	// +kubebuilder:validation:Enum=deploymentconfig;knative
+	// +optional
+	Runtime string `json:"runtime,omitempty"`
+	// Knative configures the Knative Service when Runtime is knative.
+	// +optional
+	Knative StarterKitSpecKnative `json:"knative,omitempty"`
+	// Exposure selects an alternative to the OpenShift Route for exposing the application.
+	// +optional
+	Exposure StarterKitSpecExposure `json:"exposure,omitempty"`
+	// NetworkPolicy restricts the traffic of the application pods when set.
+	// +optional
+	NetworkPolicy *StarterKitSpecNetworkPolicy `json:"networkPolicy,omitempty"`
+	// Webhook configures the webhook created on the target repo.
+	// +optional
+	Webhook StarterKitSpecWebhook `json:"webhook,omitempty"`
+	// Triggers selects the GitHub events of the target repo that trigger builds.
+	// +optional
+	Triggers StarterKitSpecTriggers `json:"triggers,omitempty"`
+	// Previews deploys every open pull request of the target repo to its own temporary environment.
+	// +optional
+	Previews StarterKitSpecPreviews `json:"previews,omitempty"`
+	// Reporting configures what is reported back to the target repo.
+	// +optional
+	Reporting StarterKitSpecReporting `json:"reporting,omitempty"`
+	// Environments are the environments the built image is promoted through, in order. Each environment runs the
+	// application in its own namespace from the image promoted to it.
+	// +optional
+	Environments []StarterKitSpecEnvironment `json:"environments,omitempty"`
+	// PinnedImage is the digest of a previously built image, e.g. one listed in status.imageHistory, to roll back to.
+	// While set, new images are not rolled out automatically.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	PinnedImage string `json:"pinnedImage,omitempty"`
+	// Paused stops the operator from changing the resources of the StarterKit, and pauses its rollouts and the builds
+	// triggered by its webhook until it is resumed.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// Bindings are the IBM Cloud Operator Bindings whose credentials are injected into the application. The
+	// application is deployed once all Bindings are Online.
+	// +optional
+	Bindings []StarterKitSpecBinding `json:"bindings,omitempty"`
+	// Services are the IBM Cloud services provisioned for the application through the IBM Cloud Operator. A Service
+	// and a Binding of the same name are created for each of them, and the credentials of the Binding are injected
+	// into the application like those of Bindings.
+	// +optional
+	Services []StarterKitSpecService `json:"services,omitempty"`
+	// ServiceBindings are the backing services whose binding secrets are projected into the application following
+	// the Service Binding Specification for Kubernetes (https://servicebinding.io).
+	// +optional
+	ServiceBindings []StarterKitSpecServiceBinding `json:"serviceBindings,omitempty"`
+}
+
+// ConditionServiceBindingsReady is the type of the condition reporting whether the binding secrets of the service
+// bindings of the StarterKit are available
+const ConditionServiceBindingsReady = "ServiceBindingsReady"
+
+// StarterKitSpecServiceBinding references the binding secret of a backing service
+type StarterKitSpecServiceBinding struct {
+	// Name is the name of the binding, which is projected into the directory $SERVICE_BINDING_ROOT/<name>. It is
+	// limited to 47 characters, as it also names the volume of the binding secret, prefixed with "service-binding-".
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=47
+	Name string `json:"name"`
+	// Service is a Provisioned Service in the namespace of the StarterKit, whose status.binding.name is the name of
+	// its binding secret
+	// +optional
+	Service *StarterKitSpecServiceReference `json:"service,omitempty"`
+	// SecretName is the name of a binding secret in the namespace of the StarterKit. Used when Service is not set.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// StarterKitSpecServiceReference references a Provisioned Service
+type StarterKitSpecServiceReference struct {
+	// APIVersion is the API version of the Provisioned Service, e.g. postgres.example.com/v1
+	APIVersion string `json:"apiVersion"`
+	// Kind is the kind of the Provisioned Service
+	Kind string `json:"kind"`
+	// Name is the name of the Provisioned Service
+	Name string `json:"name"`
+}
+
+// Deletion policies of IBM Cloud services
+const (
+	// DeletionPolicyDelete deletes the IBM Cloud service together with the StarterKit
+	DeletionPolicyDelete = "Delete"
+	// DeletionPolicyRetain keeps the IBM Cloud service when the StarterKit is deleted
+	DeletionPolicyRetain = "Retain"
+)
+
+// ConditionServicesReady is the type of the condition reporting whether the IBM Cloud services of the StarterKit are
+// Online
+const ConditionServicesReady = "ServicesReady"
+
+// StarterKitSpecService describes an IBM Cloud service provisioned for the application
+type StarterKitSpecService struct {
+	// Name is the name of the IBM Cloud Operator Service and Binding. It is limited to 55 characters, as it also
+	// names the volume the Binding secret is mounted from, prefixed with "binding-".
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=55
+	Name string `json:"name"`
+	// ServiceClass is the IBM Cloud catalog name of the service, e.g. cloudantnosqldb
+	ServiceClass string `json:"serviceClass"`
+	// Plan is the plan of the service, e.g. lite
+	Plan string `json:"plan"`
+	// MountPath is the directory the credentials of the service are mounted in as files. The credentials are
+	// injected as environment variables if not set.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+	// DeletionPolicy tells whether the service is deleted together with the StarterKit or retained. Defaults to
+	// Delete.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+}
+
+// ConditionBindingsReady is the type of the condition reporting whether the Bindings of the StarterKit are Online
+const ConditionBindingsReady = "BindingsReady"
+
+// StarterKitSpecBinding references an IBM Cloud Operator Binding in the namespace of the StarterKit
+type StarterKitSpecBinding struct {
+	// Name is the name of the Binding. It is limited to 55 characters, as it also names the volume the Binding
+	// secret is mounted from, prefixed with "binding-".
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=55
+	Name string `json:"name"`
+	// MountPath is the directory the keys of the Binding secret are mounted in as files. The keys are injected as
+	// environment variables if not set.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// StarterKitSpecReporting configures what is reported back to the target repo
+type StarterKitSpecReporting struct {
+	// CommitStatuses posts the results of builds and rollouts as commit statuses on the built commits.
+	// +optional
+	CommitStatuses bool `json:"commitStatuses,omitempty"`
+	// Deployments records every successful rollout of the DeploymentConfig as a GitHub deployment of the environment.
+	// Rollouts of the Knative runtime are not recorded.
+	// +optional
+	Deployments bool `json:"deployments,omitempty"`
+	// Environment is the name of the GitHub environment rollouts are recorded in. Defaults to the namespace of the
+	// StarterKit.
+	// +optional
+	Environment string `json:"environment,omitempty"`
+}
+
+// ConditionCommitStatusesReported is the type of the condition reporting whether the results of builds and rollouts
+// were posted as commit statuses
+const ConditionCommitStatusesReported = "CommitStatusesReported"
+
+// ConditionDeploymentsReported is the type of the condition reporting whether successful rollouts were recorded as
+// GitHub deployments
+const ConditionDeploymentsReported = "DeploymentsReported"
+
+// Promotion policies of an environment
+const (
+	// PromotionAutomatic promotes every new image of the previous environment
+	PromotionAutomatic = "Automatic"
+	// PromotionManual promotes the image of the previous environment when approved with the promote annotation
+	PromotionManual = "Manual"
+)
+
+// PromoteAnnotationPrefix prefixes the name of the environment in the annotation approving a manual promotion into
+// it, e.g. "devx.ibm.com/promote-prod". The pending image is promoted whenever the value of the annotation changes.
+const PromoteAnnotationPrefix = "devx.ibm.com/promote-"
+
+// EnvironmentSourceLabel is set on a namespace to allow the StarterKits of the namespace named by its value to
+// deploy environments into it, e.g. "devx.ibm.com/environment-source: my-app-dev"
+const EnvironmentSourceLabel = "devx.ibm.com/environment-source"
+
+// ConditionEnvironmentsAllowed is the type of the condition reporting whether the namespaces of all environments of
+// the StarterKit may be deployed to
+const ConditionEnvironmentsAllowed = "EnvironmentsAllowed"
+
+// StarterKitSpecEnvironment describes an environment the built image is promoted to
+type StarterKitSpecEnvironment struct {
+	// Name is the name of the environment, e.g. "staging". It is also the ImageStream tag of the image promoted to it.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+	// Namespace is the namespace the application is deployed to in this environment. It must differ from the
+	// namespace of the StarterKit and of the other environments, and must be labeled with EnvironmentSourceLabel set
+	// to the namespace of the StarterKit.
+	Namespace string `json:"namespace"`
+	// Promotion is the policy promoting the image of the previous environment, or of the latest build for the first
+	// environment, into this one. Defaults to Automatic.
+	// +optional
+	// +kubebuilder:validation:Enum=Automatic;Manual
+	Promotion string `json:"promotion,omitempty"`
+	// Host is the hostname the application is exposed on in this environment. Generated by the router if unset.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Env are environment variables set in addition to, or overriding, the ones in options.env.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// StarterKitSpecPreviews configures the preview environments of pull requests
+type StarterKitSpecPreviews struct {
+	// Enabled creates a build, deployment and route for every open pull request and comments the preview URL on it.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Label is the pull request label with which maintainers opt in a pull request whose author is not an owner,
+	// member or collaborator of the target repo, and whose head is in another repo such as a fork. Other pull
+	// requests are previewed without it. Defaults to "preview".
+	// +optional
+	Label string `json:"label,omitempty"`
+}
+
+// StarterKitSpecTriggers selects the GitHub events that trigger builds
+type StarterKitSpecTriggers struct {
+	// Branches are glob patterns of the branches whose pushes are built into the latest image. Defaults to the
+	// branch followed by the BuildConfig.
+	// +optional
+	Branches []string `json:"branches,omitempty"`
+	// Tags are glob patterns of the git tags whose pushes are built into an image tagged with the git tag, e.g. "v*".
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// Releases builds published GitHub releases into an image tagged with the release tag.
+	// +optional
+	Releases bool `json:"releases,omitempty"`
+	// PullRequests builds the head commit of opened and updated pull requests into an image tagged "pr-<number>".
+	// +optional
+	PullRequests bool `json:"pullRequests,omitempty"`
+}
+
+// RotateWebhookSecretAnnotation triggers a rotation of the webhook secret whenever its value changes
+const RotateWebhookSecretAnnotation = "devx.ibm.com/rotate-webhook-secret"
+
+// ConditionPaused is the type of the condition reporting whether the StarterKit is paused
+const ConditionPaused = "Paused"
+
+// ConditionTemplateFound is the type of the condition reporting whether the StarterKitTemplate referenced by the
+// StarterKit exists
+const ConditionTemplateFound = "TemplateFound"
+
+// RebuildAnnotation triggers a new build of the application whenever its value changes
+const RebuildAnnotation = "devx.ibm.com/rebuild"
+
+// RedeployAnnotation triggers a new rollout of the application whenever its value changes
+const RedeployAnnotation = "devx.ibm.com/redeploy"
+
+// ConditionManualTriggersStarted is the type of the condition reporting whether the last requested build and rollout
+// were started
+const ConditionManualTriggersStarted = "ManualTriggersStarted"
+
+// StarterKitSpecWebhook configures the webhook created on the target repo
+type StarterKitSpecWebhook struct {
+	// SecretRotationInterval rotates the webhook secret periodically when set to a positive duration, e.g. "720h".
+	// +optional
+	SecretRotationInterval *metav1.Duration `json:"secretRotationInterval,omitempty"`
+}
+
+// StarterKitSpecNetworkPolicy configures the NetworkPolicy generated for the application
+type StarterKitSpecNetworkPolicy struct {
+	// RouterNamespaceSelector selects the namespaces of the router or ingress controller allowed to reach the
+	// application. Defaults to the OpenShift ingress policy group.
+	// +optional
+	RouterNamespaceSelector *metav1.LabelSelector `json:"routerNamespaceSelector,omitempty"`
+	// From lists additional peers allowed to reach the application ports.
+	// +optional
+	From []networkingv1.NetworkPolicyPeer `json:"from,omitempty"`
+	// Egress lists the outgoing traffic allowed from the application. Egress is not restricted when empty.
+	// +optional
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+}
+
+// StarterKitSpecExposure selects how the application is exposed outside the cluster
+type StarterKitSpecExposure struct {
+	// GatewayAPI exposes the application through a Gateway API HTTPRoute instead of an OpenShift Route.
+	// +optional
+	GatewayAPI *StarterKitSpecGatewayAPI `json:"gatewayAPI,omitempty"`
+}
+
+// StarterKitSpecGatewayAPI configures the HTTPRoute exposing the application
+type StarterKitSpecGatewayAPI struct {
+	// GatewayRef is the Gateway the HTTPRoute attaches to.
+	GatewayRef StarterKitSpecGatewayRef `json:"gatewayRef"`
+	// Hostnames the HTTPRoute matches. When empty the hostnames of the Gateway listener are used.
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+	// Paths are the path prefixes routed to the application. Defaults to "/".
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+}
+
+// StarterKitSpecGatewayRef references a Gateway API Gateway
+type StarterKitSpecGatewayRef struct {
+	Name string `json:"name"`
+	// Namespace of the Gateway. Defaults to the StarterKit namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// SectionName selects a single listener of the Gateway.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+const (
+	// RuntimeDeploymentConfig deploys the application as a DeploymentConfig with a Service and Route
+	RuntimeDeploymentConfig = "deploymentconfig"
+	// RuntimeKnative deploys the application as a Knative Service
+	RuntimeKnative = "knative"
+)
+
+// StarterKitSpecKnative configures the autoscaling of a Knative Service
+type StarterKitSpecKnative struct {
+	// ContainerConcurrency is the maximum number of concurrent requests per pod. Zero means unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ContainerConcurrency *int64 `json:"containerConcurrency,omitempty"`
+	// MinScale is the minimum number of pods. The application scales to zero when idle if this is zero or unset.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinScale *int32 `json:"minScale,omitempty"`
+	// MaxScale is the maximum number of pods. Zero or unset means unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxScale *int32 `json:"maxScale,omitempty"`
+	// ScaleToZeroPodRetentionPeriod keeps the last pod around for the given duration (e.g. "5m") after the
+	// application became idle before scaling to zero.
+	// +optional
+	ScaleToZeroPodRetentionPeriod string `json:"scaleToZeroPodRetentionPeriod,omitempty"`
 }
 
 type StarterKitSpecOptions struct {
 	Port int32           `json:"port"`
 	Env  []corev1.EnvVar `json:"env"`
+
+	// Ports lists the named ports exposed by the application. When set it
+	// takes precedence over Port.
+	// +optional
+	Ports []StarterKitSpecPort `json:"ports,omitempty"`
+	// RoutePort is the name of the port the Route targets. Defaults to the
+	// first entry in Ports.
+	// +optional
+	RoutePort string `json:"routePort,omitempty"`
+	// BuildStrategy selects how the application image is built. Defaults to the Docker strategy.
+	// +optional
+	BuildStrategy *StarterKitSpecBuildStrategy `json:"buildStrategy,omitempty"`
+	// LivenessProbe is the liveness probe of the application container
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+	// ReadinessProbe is the readiness probe of the application container
+	// +optional
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+}
+
+// ManifestPath is the path of the manifest describing the application in a template repo
+const ManifestPath = ".starterkit.yaml"
+
+// StarterKitManifest describes the application of a template repo. It is read from the ManifestPath file of the
+// template repo and provides defaults for the fields that are neither set on the StarterKit nor on its template.
+type StarterKitManifest struct {
+	// Port is the port the application listens on
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// Env are the environment variables of the application
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// LivenessProbe is the liveness probe of the application container
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+	// ReadinessProbe is the readiness probe of the application container
+	// +optional
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+	// BuildStrategy selects how the application image is built
+	// +optional
+	BuildStrategy *StarterKitSpecBuildStrategy `json:"buildStrategy,omitempty"`
+	// Bindings are the names of the IBM Cloud Operator Bindings the application requires
+	// +optional
+	Bindings []string `json:"bindings,omitempty"`
+	// ParameterFiles are the files of the template repo containing parameter placeholders. Defaults to
+	// package.json, pom.xml and README.md.
+	// +optional
+	ParameterFiles []string `json:"parameterFiles,omitempty"`
+}
+
+// Build strategy types
+const (
+	// BuildStrategyDocker builds the application image from a Dockerfile
+	BuildStrategyDocker = "Docker"
+	// BuildStrategySource builds the application image with a Source-to-Image builder image
+	BuildStrategySource = "Source"
+)
+
+// StarterKitSpecBuildStrategy selects how the application image is built
+type StarterKitSpecBuildStrategy struct {
+	// Type is the build strategy.
+	// +kubebuilder:validation:Enum=Docker;Source
+	Type string `json:"type"`
+	// DockerfilePath is the path of the Dockerfile used by the Docker strategy. Defaults to "Dockerfile".
+	// +optional
+	DockerfilePath string `json:"dockerfilePath,omitempty"`
+	// BuilderImage is the Source-to-Image builder image used by the Source strategy, e.g. the ImageStreamTag
+	// "nodejs:14-ubi8" in the "openshift" namespace.
+	// +optional
+	BuilderImage *corev1.ObjectReference `json:"builderImage,omitempty"`
+}
+
+// StarterKitSpecPort describes a single named port exposed by the application
+type StarterKitSpecPort struct {
+	// +kubebuilder:validation:MaxLength=15
+	Name string `json:"name"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
+	// +optional
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+}
+
+// StarterKitSpecRoute configures the Route that exposes the application
+type StarterKitSpecRoute struct {
+	// Host is the hostname of the Route. When empty a hostname is generated by the router.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Path restricts the Route to requests with the given path prefix.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Termination enables TLS on the Route. The Route is served over plain HTTP when empty.
+	// +kubebuilder:validation:Enum=edge;passthrough;reencrypt
+	// +optional
+	Termination string `json:"termination,omitempty"`
+	// InsecureEdgeTerminationPolicy controls what happens to plain HTTP requests when TLS is enabled.
+	// Defaults to Redirect.
+	// +kubebuilder:validation:Enum=None;Allow;Redirect
+	// +optional
+	InsecureEdgeTerminationPolicy string `json:"insecureEdgeTerminationPolicy,omitempty"`
+	// CertificateSecretRef references a Secret in the StarterKit namespace holding the tls.crt, tls.key
+	// and optionally ca.crt and destination-ca.crt keys used for the Route TLS configuration.
+	// +optional
+	CertificateSecretRef *corev1.LocalObjectReference `json:"certificateSecretRef,omitempty"`
+	// Annotations are added to the Route, e.g. to configure router timeouts.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// IssuerRef requests a certificate for Host from a cert-manager issuer. The issued certificate is stored in the
+	// Secret named by CertificateSecretRef, or "<name>-tls" when none is given, and used for the Route TLS configuration.
+	// +optional
+	IssuerRef *StarterKitSpecIssuerRef `json:"issuerRef,omitempty"`
+}
+
+// StarterKitSpecIssuerRef references a cert-manager Issuer or ClusterIssuer
+type StarterKitSpecIssuerRef struct {
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// +optional
+	Group string `json:"group,omitempty"`
 }
 
 type StarterKitSpecTemplate struct {
-	TemplateOwner    string                   `json:"templateOwner"`
-	TemplateRepoName string                   `json:"templateRepoName"`
-	Owner            string                   `json:"owner"`
-	Name             string                   `json:"name"`
-	Description      string                   `json:"repoDescription"`
-	SecretKeyRef     corev1.SecretKeySelector `json:"secretKeyRef"`
+	// TemplateOwner is the owner of the GitHub template repo. Provided by the StarterKitTemplate when templateRef is set.
+	// +optional
+	TemplateOwner string `json:"templateOwner,omitempty"`
+	// TemplateRepoName is the name of the GitHub template repo. Provided by the StarterKitTemplate when templateRef is
+	// set.
+	// +optional
+	TemplateRepoName string `json:"templateRepoName,omitempty"`
+	Owner            string `json:"owner"`
+	Name             string `json:"name"`
+	// +optional
+	Description  string                   `json:"repoDescription,omitempty"`
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+	// Parameters are the values of the "{{starterkit.<key>}}" placeholders in the files of the template repo. They
+	// are applied once the target repo is created, in a single "Initialize from template" commit. Parameters added or
+	// changed after the target repo was created are ignored.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
 }
 
 // StarterKitStatus defines the observed state of StarterKit
@@ -53,10 +518,174 @@ type StarterKitStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	TargetRepo string `json:"targetRepo"`
+	// URL is the resolved URL of the application Route
+	// +optional
+	URL string `json:"url,omitempty"`
+	// Manifest is the manifest read from the template repo when the target repo was created
+	// +optional
+	Manifest *StarterKitManifest `json:"manifest,omitempty"`
+	// InitializationCommit is the commit applying the parameters to the target repo, or the generated commit if the
+	// target repo has no parameter placeholders
+	// +optional
+	InitializationCommit string `json:"initializationCommit,omitempty"`
+	// ParametersPending is true while the parameters have not been applied to the newly created target repo yet
+	// +optional
+	ParametersPending bool `json:"parametersPending,omitempty"`
+	// WebhookID is the ID of the webhook created on the target repo
+	// +optional
+	WebhookID int64 `json:"webhookID,omitempty"`
+	// Conditions describe the state of the StarterKit
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Environments describe the images promoted to the environments
+	// +optional
+	Environments []StarterKitStatusEnvironment `json:"environments,omitempty"`
+	// GitHubDeploymentID is the ID of the GitHub deployment recording the latest successful rollout
+	// +optional
+	GitHubDeploymentID int64 `json:"githubDeploymentID,omitempty"`
+	// PullRequests are the open pull requests of the target repo with a preview environment
+	// +optional
+	PullRequests []StarterKitStatusPullRequest `json:"pullRequests,omitempty"`
+	// RebuildRequest is the last handled value of the rebuild annotation
+	// +optional
+	RebuildRequest string `json:"rebuildRequest,omitempty"`
+	// RedeployRequest is the last handled value of the redeploy annotation
+	// +optional
+	RedeployRequest string `json:"redeployRequest,omitempty"`
+	// WebhookEvents are the GitHub events the webhook created on the target repo subscribes to
+	// +optional
+	WebhookEvents []string `json:"webhookEvents,omitempty"`
+	// WebhookSecretRotationTimestamp is the time the webhook secret was last rotated
+	// +optional
+	WebhookSecretRotationTimestamp *metav1.Time `json:"webhookSecretRotationTimestamp,omitempty"`
+	// WebhookSecretRotationRequest is the last handled value of the rotate-webhook-secret annotation
+	// +optional
+	WebhookSecretRotationRequest string `json:"webhookSecretRotationRequest,omitempty"`
+	// Services describe the state of the IBM Cloud services provisioned for the StarterKit
+	// +optional
+	Services []StarterKitStatusService `json:"services,omitempty"`
+	// ServiceBindings describe the binding secrets projected into the application
+	// +optional
+	ServiceBindings []StarterKitStatusServiceBinding `json:"serviceBindings,omitempty"`
+	// Bindings describe the state of the IBM Cloud Operator Bindings of the StarterKit
+	// +optional
+	Bindings []StarterKitStatusBinding `json:"bindings,omitempty"`
+	// LatestBuild describes the most recent Build of the application
+	// +optional
+	LatestBuild *StarterKitStatusBuild `json:"latestBuild,omitempty"`
+	// ImageDigest is the digest of the image currently tagged latest in the ImageStream
+	// +optional
+	ImageDigest string `json:"imageDigest,omitempty"`
+	// ImageHistory lists the most recently built images, newest first
+	// +optional
+	ImageHistory []StarterKitStatusImage `json:"imageHistory,omitempty"`
+	// LatestRevision is the latest version of the DeploymentConfig that was rolled out
+	// +optional
+	LatestRevision int64 `json:"latestRevision,omitempty"`
+	// AvailableReplicas is the number of available application pods
+	// +optional
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+}
+
+// StarterKitStatusEnvironment describes the state of an environment
+type StarterKitStatusEnvironment struct {
+	// Name is the name of the environment
+	Name string `json:"name"`
+	// Digest is the digest of the image promoted to the environment
+	// +optional
+	Digest string `json:"digest,omitempty"`
+	// PendingDigest is the digest of the image waiting for approval to be promoted to the environment
+	// +optional
+	PendingDigest string `json:"pendingDigest,omitempty"`
+	// PromotionRequest is the last handled value of the promote annotation of the environment
+	// +optional
+	PromotionRequest string `json:"promotionRequest,omitempty"`
+	// PromotionTimestamp is the time the image was promoted to the environment
+	// +optional
+	PromotionTimestamp *metav1.Time `json:"promotionTimestamp,omitempty"`
+	// URL is the URL the application is exposed on in the environment
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// StarterKitStatusService describes the state of an IBM Cloud service
+type StarterKitStatusService struct {
+	// Name is the name of the IBM Cloud Operator Service
+	Name string `json:"name"`
+	// State is the state of the Service, e.g. Online
+	// +optional
+	State string `json:"state,omitempty"`
+}
+
+// StarterKitStatusServiceBinding describes the binding secret of a service binding
+type StarterKitStatusServiceBinding struct {
+	// Name is the name of the service binding
+	Name string `json:"name"`
+	// SecretName is the name of the binding secret projected into the application
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// StarterKitStatusBinding describes the state of an IBM Cloud Operator Binding
+type StarterKitStatusBinding struct {
+	// Name is the name of the Binding
+	Name string `json:"name"`
+	// State is the state of the Binding, e.g. Online
+	// +optional
+	State string `json:"state,omitempty"`
+	// SecretName is the name of the Secret holding the credentials of the Binding
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// StarterKitStatusImage describes a built image
+type StarterKitStatusImage struct {
+	// Digest is the digest of the image
+	Digest string `json:"digest"`
+	// Commit is the commit the image was built from
+	// +optional
+	Commit string `json:"commit,omitempty"`
+	// Created is the time the image was pushed
+	// +optional
+	Created metav1.Time `json:"created,omitempty"`
+}
+
+// StarterKitStatusPullRequest describes the preview environment of a pull request
+type StarterKitStatusPullRequest struct {
+	// Number is the number of the pull request
+	Number int `json:"number"`
+	// HeadSHA is the latest commit of the pull request
+	// +optional
+	HeadSHA string `json:"headSHA,omitempty"`
+	// URL is the URL the preview is served on
+	// +optional
+	URL string `json:"url,omitempty"`
+	// CommentID is the ID of the comment announcing the preview URL on the pull request
+	// +optional
+	CommentID int64 `json:"commentID,omitempty"`
+	// SkipReason explains why no preview is deployed for the pull request
+	// +optional
+	SkipReason string `json:"skipReason,omitempty"`
+}
+
+// StarterKitStatusBuild describes a Build of the application
+type StarterKitStatusBuild struct {
+	Name  string `json:"name"`
+	Phase string `json:"phase"`
+	// +optional
+	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
+	// +optional
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.url`
+// +kubebuilder:printcolumn:name="Build",type=string,JSONPath=`.status.latestBuild.phase`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.availableReplicas`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // StarterKit is the Schema for the starterkits API
 type StarterKit struct {
@@ -0,0 +1,254 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var starterkitlog = logf.Log.WithName("starterkit-resource")
+
+var (
+	// githubOwnerRegexp matches the GitHub user and organization names
+	githubOwnerRegexp = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,37}[A-Za-z0-9])?$`)
+	// githubRepoRegexp matches the GitHub repo names
+	githubRepoRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+)
+
+// SetupWebhookWithManager registers the validating webhook of the StarterKit with the manager.
+func (r *StarterKit) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&starterKitValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-devx-ibm-com-v1alpha1-starterkit,mutating=false,failurePolicy=fail,sideEffects=None,groups=devx.ibm.com,resources=starterkits,verbs=create;update,versions=v1alpha1,name=vstarterkit.kb.io,admissionReviewVersions={v1,v1beta1}
+
+// starterKitValidator validates StarterKits before they are stored, so that invalid specs are rejected instead of
+// failing deep inside Reconcile after a GitHub call
+type starterKitValidator struct {
+	Client client.Client
+}
+
+var _ admission.CustomValidator = &starterKitValidator{}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *starterKitValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	skit, ok := obj.(*StarterKit)
+	if !ok {
+		return fmt.Errorf("expected a StarterKit but got a %T", obj)
+	}
+	starterkitlog.Info("validate create", "name", skit.Name)
+
+	allErrs := validateStarterKitSpec(&skit.Spec, skit.Namespace)
+	dupErrs, err := v.validateTargetRepo(ctx, skit)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	allErrs = append(allErrs, dupErrs...)
+	return toInvalidError(skit, allErrs)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *starterKitValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	skit, ok := newObj.(*StarterKit)
+	if !ok {
+		return fmt.Errorf("expected a StarterKit but got a %T", newObj)
+	}
+	old, ok := oldObj.(*StarterKit)
+	if !ok {
+		return fmt.Errorf("expected a StarterKit but got a %T", oldObj)
+	}
+	starterkitlog.Info("validate update", "name", skit.Name)
+
+	// Only the finalizer is removed from StarterKits that are being deleted. Updates that leave the spec alone, like
+	// adding the finalizer, are let through as well, so that StarterKits created before the webhook can still be
+	// reconciled and deleted.
+	if skit.GetDeletionTimestamp() != nil || equality.Semantic.DeepEqual(&old.Spec, &skit.Spec) {
+		return nil
+	}
+	allErrs := validateStarterKitSpec(&skit.Spec, skit.Namespace)
+	if old.Status.TargetRepo != "" {
+		allErrs = append(allErrs, validateImmutableFields(&skit.Spec, &old.Spec)...)
+	} else {
+		// The target repo can still be changed to one of another StarterKit until it is created
+		dupErrs, err := v.validateTargetRepo(ctx, skit)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, dupErrs...)
+	}
+	return toInvalidError(skit, allErrs)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *starterKitValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// Returns an Invalid error for the specified StarterKit listing the errors, or nil if there are none.
+func toInvalidError(skit *StarterKit, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("StarterKit").GroupKind(), skit.Name, allErrs)
+}
+
+// Checks the formats of the fields of the spec of a StarterKit in the specified namespace.
+func validateStarterKitSpec(spec *StarterKitSpec, namespace string) field.ErrorList {
+	var allErrs field.ErrorList
+	repoPath := field.NewPath("spec", "templateRepo")
+
+	allErrs = append(allErrs, validateGitHubOwner(spec.TemplateRepo.Owner, repoPath.Child("owner"))...)
+	allErrs = append(allErrs, validateGitHubRepo(spec.TemplateRepo.Name, repoPath.Child("name"))...)
+	if spec.TemplateRef == "" || spec.TemplateRepo.TemplateOwner != "" || spec.TemplateRepo.TemplateRepoName != "" {
+		allErrs = append(allErrs, validateGitHubOwner(spec.TemplateRepo.TemplateOwner, repoPath.Child("templateOwner"))...)
+		allErrs = append(allErrs, validateGitHubRepo(spec.TemplateRepo.TemplateRepoName, repoPath.Child("templateRepoName"))...)
+	}
+	if spec.TemplateRepo.SecretKeyRef.Name == "" {
+		allErrs = append(allErrs, field.Required(repoPath.Child("secretKeyRef", "name"), "the Secret holding the GitHub access token is required"))
+	}
+	if spec.TemplateRepo.SecretKeyRef.Key == "" {
+		allErrs = append(allErrs, field.Required(repoPath.Child("secretKeyRef", "key"), "the key of the GitHub access token is required"))
+	}
+
+	optionsPath := field.NewPath("spec", "options")
+	if spec.Options.Port < 0 || spec.Options.Port > 65535 {
+		allErrs = append(allErrs, field.Invalid(optionsPath.Child("port"), spec.Options.Port, "must be between 1 and 65535, or 0 for the default port"))
+	}
+	portNames := map[string]bool{}
+	for i, p := range spec.Options.Ports {
+		path := optionsPath.Child("ports").Index(i)
+		if p.Port < 1 || p.Port > 65535 {
+			allErrs = append(allErrs, field.Invalid(path.Child("port"), p.Port, "must be between 1 and 65535"))
+		}
+		if portNames[p.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("name"), p.Name))
+		}
+		portNames[p.Name] = true
+	}
+	// Without ports of its own, the StarterKit may take them from its template or manifest
+	if spec.Options.RoutePort != "" && len(spec.Options.Ports) > 0 && !portNames[spec.Options.RoutePort] {
+		allErrs = append(allErrs, field.NotFound(optionsPath.Child("routePort"), spec.Options.RoutePort))
+	}
+	if s := spec.Options.BuildStrategy; s != nil && s.Type == BuildStrategySource && s.BuilderImage == nil {
+		allErrs = append(allErrs, field.Required(optionsPath.Child("buildStrategy", "builderImage"), "the Source strategy requires a builder image"))
+	}
+
+	for i, sb := range spec.ServiceBindings {
+		path := field.NewPath("spec", "serviceBindings").Index(i)
+		if (sb.Service == nil) == (sb.SecretName == "") {
+			allErrs = append(allErrs, field.Invalid(path, sb.Name, "exactly one of service and secretName must be set"))
+		}
+	}
+
+	names := map[string]bool{}
+	namespaces := map[string]bool{}
+	for i, env := range spec.Environments {
+		path := field.NewPath("spec", "environments").Index(i)
+		if names[env.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("name"), env.Name))
+		}
+		names[env.Name] = true
+		switch {
+		case env.Namespace == "":
+			allErrs = append(allErrs, field.Required(path.Child("namespace"), ""))
+		case env.Namespace == namespace:
+			allErrs = append(allErrs, field.Invalid(path.Child("namespace"), env.Namespace, "must differ from the namespace of the StarterKit"))
+		case namespaces[env.Namespace]:
+			allErrs = append(allErrs, field.Duplicate(path.Child("namespace"), env.Namespace))
+		}
+		namespaces[env.Namespace] = true
+	}
+	return allErrs
+}
+
+// Checks the format of a GitHub user or organization name.
+func validateGitHubOwner(owner string, path *field.Path) field.ErrorList {
+	if owner == "" {
+		return field.ErrorList{field.Required(path, "")}
+	}
+	if !githubOwnerRegexp.MatchString(owner) || strings.Contains(owner, "--") {
+		return field.ErrorList{field.Invalid(path, owner, "must be a valid GitHub user or organization name")}
+	}
+	return nil
+}
+
+// Checks the format of a GitHub repo name.
+func validateGitHubRepo(repo string, path *field.Path) field.ErrorList {
+	if repo == "" {
+		return field.ErrorList{field.Required(path, "")}
+	}
+	if !githubRepoRegexp.MatchString(repo) || repo == "." || repo == ".." {
+		return field.ErrorList{field.Invalid(path, repo, "must consist of at most 100 letters, digits, '.', '-' or '_'")}
+	}
+	return nil
+}
+
+// Checks that the fields identifying the template and target repos are not changed. Only applies once the target
+// repo exists.
+func validateImmutableFields(spec *StarterKitSpec, old *StarterKitSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	repoPath := field.NewPath("spec", "templateRepo")
+	fields := []struct {
+		path     *field.Path
+		new, old string
+	}{
+		{field.NewPath("spec", "templateRef"), spec.TemplateRef, old.TemplateRef},
+		{repoPath.Child("templateOwner"), spec.TemplateRepo.TemplateOwner, old.TemplateRepo.TemplateOwner},
+		{repoPath.Child("templateRepoName"), spec.TemplateRepo.TemplateRepoName, old.TemplateRepo.TemplateRepoName},
+		{repoPath.Child("owner"), spec.TemplateRepo.Owner, old.TemplateRepo.Owner},
+		{repoPath.Child("name"), spec.TemplateRepo.Name, old.TemplateRepo.Name},
+	}
+	for _, f := range fields {
+		if f.new != f.old {
+			allErrs = append(allErrs, field.Forbidden(f.path, "field is immutable"))
+		}
+	}
+	return allErrs
+}
+
+// Checks that no other StarterKit in the cluster targets the same GitHub repo.
+func (v *starterKitValidator) validateTargetRepo(ctx context.Context, skit *StarterKit) (field.ErrorList, error) {
+	skits := &StarterKitList{}
+	if err := v.Client.List(ctx, skits); err != nil {
+		return nil, err
+	}
+	for _, other := range skits.Items {
+		if other.Namespace == skit.Namespace && other.Name == skit.Name {
+			continue
+		}
+		if strings.EqualFold(other.Spec.TemplateRepo.Owner, skit.Spec.TemplateRepo.Owner) && strings.EqualFold(other.Spec.TemplateRepo.Name, skit.Spec.TemplateRepo.Name) {
+			return field.ErrorList{field.Duplicate(field.NewPath("spec", "templateRepo", "name"), fmt.Sprintf("%s/%s is the target repo of StarterKit %s/%s", skit.Spec.TemplateRepo.Owner, skit.Spec.TemplateRepo.Name, other.Namespace, other.Name))}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,425 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// Returns a valid StarterKit to derive the test cases from.
+func newTestStarterKit() *StarterKit {
+	return &StarterKit{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-app",
+			Namespace: "dev",
+		},
+		Spec: StarterKitSpec{
+			TemplateRepo: StarterKitSpecTemplate{
+				TemplateOwner:    "IBM",
+				TemplateRepoName: "java-spring-app",
+				Owner:            "devx-test",
+				Name:             "my-app",
+				SecretKeyRef: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "github-token"},
+					Key:                  "apikey",
+				},
+			},
+		},
+	}
+}
+
+// Returns the paths of the fields with errors.
+func errorPaths(allErrs field.ErrorList) []string {
+	var paths []string
+	for _, err := range allErrs {
+		paths = append(paths, err.Field)
+	}
+	return paths
+}
+
+// Fails the test unless the errors are reported for exactly the given fields, in order.
+func expectErrorPaths(t *testing.T, allErrs field.ErrorList, want []string) {
+	t.Helper()
+	got := errorPaths(allErrs)
+	if len(got) != len(want) {
+		t.Fatalf("expected errors for %v, got %v", want, allErrs)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected errors for %v, got %v", want, allErrs)
+		}
+	}
+}
+
+func TestValidateGitHubOwner(t *testing.T) {
+	tests := []struct {
+		owner string
+		valid bool
+	}{
+		{"IBM", true},
+		{"devx-test", true},
+		{"a", true},
+		{"a123456789012345678901234567890123456789", false},
+		{"", false},
+		{"-devx", false},
+		{"devx-", false},
+		{"devx--test", false},
+		{"devx_test", false},
+		{"devx.test", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.owner, func(t *testing.T) {
+			allErrs := validateGitHubOwner(tt.owner, field.NewPath("owner"))
+			if valid := len(allErrs) == 0; valid != tt.valid {
+				t.Errorf("validateGitHubOwner(%q) = %v, expected valid to be %v", tt.owner, allErrs, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateGitHubRepo(t *testing.T) {
+	tests := []struct {
+		repo  string
+		valid bool
+	}{
+		{"java-spring-app", true},
+		{"my_app.v2", true},
+		{".github", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"my app", false},
+		{"my/app", false},
+		{strings.Repeat("a", 101), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			allErrs := validateGitHubRepo(tt.repo, field.NewPath("name"))
+			if valid := len(allErrs) == 0; valid != tt.valid {
+				t.Errorf("validateGitHubRepo(%q) = %v, expected valid to be %v", tt.repo, allErrs, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateStarterKitSpec(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(spec *StarterKitSpec)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(spec *StarterKitSpec) {},
+		},
+		{
+			name: "template reference without template repo",
+			modify: func(spec *StarterKitSpec) {
+				spec.TemplateRef = "java-spring-app"
+				spec.TemplateRepo.TemplateOwner = ""
+				spec.TemplateRepo.TemplateRepoName = ""
+			},
+		},
+		{
+			name: "missing template repo",
+			modify: func(spec *StarterKitSpec) {
+				spec.TemplateRepo.TemplateRepoName = ""
+			},
+			want: []string{"spec.templateRepo.templateRepoName"},
+		},
+		{
+			name: "invalid target repo",
+			modify: func(spec *StarterKitSpec) {
+				spec.TemplateRepo.Owner = "devx test"
+				spec.TemplateRepo.Name = "my/app"
+			},
+			want: []string{"spec.templateRepo.owner", "spec.templateRepo.name"},
+		},
+		{
+			name: "missing secret key reference",
+			modify: func(spec *StarterKitSpec) {
+				spec.TemplateRepo.SecretKeyRef = corev1.SecretKeySelector{}
+			},
+			want: []string{"spec.templateRepo.secretKeyRef.name", "spec.templateRepo.secretKeyRef.key"},
+		},
+		{
+			name: "invalid ports",
+			modify: func(spec *StarterKitSpec) {
+				spec.Options.Port = 70000
+				spec.Options.Ports = []StarterKitSpecPort{{Name: "http", Port: 8080}, {Name: "metrics", Port: 0}}
+			},
+			want: []string{"spec.options.port", "spec.options.ports[1].port"},
+		},
+		{
+			name: "default port",
+			modify: func(spec *StarterKitSpec) {
+				spec.Options.Port = 0
+			},
+		},
+		{
+			name: "duplicate port names",
+			modify: func(spec *StarterKitSpec) {
+				spec.Options.Ports = []StarterKitSpecPort{{Name: "http", Port: 8080}, {Name: "http", Port: 8081}}
+			},
+			want: []string{"spec.options.ports[1].name"},
+		},
+		{
+			name: "route port",
+			modify: func(spec *StarterKitSpec) {
+				spec.Options.Ports = []StarterKitSpecPort{{Name: "http", Port: 8080}, {Name: "metrics", Port: 9090}}
+				spec.Options.RoutePort = "http"
+			},
+		},
+		{
+			name: "unknown route port",
+			modify: func(spec *StarterKitSpec) {
+				spec.Options.Ports = []StarterKitSpecPort{{Name: "http", Port: 8080}}
+				spec.Options.RoutePort = "web"
+			},
+			want: []string{"spec.options.routePort"},
+		},
+		{
+			name: "route port without ports",
+			modify: func(spec *StarterKitSpec) {
+				spec.Options.RoutePort = "http"
+			},
+		},
+		{
+			name: "source strategy without builder image",
+			modify: func(spec *StarterKitSpec) {
+				spec.Options.BuildStrategy = &StarterKitSpecBuildStrategy{Type: BuildStrategySource}
+			},
+			want: []string{"spec.options.buildStrategy.builderImage"},
+		},
+		{
+			name: "service binding without or with both sources",
+			modify: func(spec *StarterKitSpec) {
+				spec.ServiceBindings = []StarterKitSpecServiceBinding{
+					{Name: "db", SecretName: "db-binding"},
+					{Name: "cache"},
+					{Name: "queue", SecretName: "queue-binding", Service: &StarterKitSpecServiceReference{APIVersion: "v1", Kind: "Queue", Name: "queue"}},
+				}
+			},
+			want: []string{"spec.serviceBindings[1]", "spec.serviceBindings[2]"},
+		},
+		{
+			name: "valid environments",
+			modify: func(spec *StarterKitSpec) {
+				spec.Environments = []StarterKitSpecEnvironment{
+					{Name: "staging", Namespace: "staging"},
+					{Name: "prod", Namespace: "prod"},
+				}
+			},
+		},
+		{
+			name: "invalid environments",
+			modify: func(spec *StarterKitSpec) {
+				spec.Environments = []StarterKitSpecEnvironment{
+					{Name: "staging", Namespace: "staging"},
+					{Name: "qa"},
+					{Name: "test", Namespace: "dev"},
+					{Name: "prod", Namespace: "staging"},
+					{Name: "staging", Namespace: "staging-2"},
+				}
+			},
+			want: []string{
+				"spec.environments[1].namespace",
+				"spec.environments[2].namespace",
+				"spec.environments[3].namespace",
+				"spec.environments[4].name",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skit := newTestStarterKit()
+			tt.modify(&skit.Spec)
+			expectErrorPaths(t, validateStarterKitSpec(&skit.Spec, skit.Namespace), tt.want)
+		})
+	}
+}
+
+func TestValidateImmutableFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(spec *StarterKitSpec)
+		want   []string
+	}{
+		{
+			name: "mutable fields changed",
+			modify: func(spec *StarterKitSpec) {
+				spec.TemplateRepo.Description = "My app"
+				spec.Options.Port = 3000
+			},
+		},
+		{
+			name: "template changed",
+			modify: func(spec *StarterKitSpec) {
+				spec.TemplateRef = "node-express-app"
+				spec.TemplateRepo.TemplateOwner = "devx-test"
+				spec.TemplateRepo.TemplateRepoName = "node-express-app"
+			},
+			want: []string{"spec.templateRef", "spec.templateRepo.templateOwner", "spec.templateRepo.templateRepoName"},
+		},
+		{
+			name: "target repo changed",
+			modify: func(spec *StarterKitSpec) {
+				spec.TemplateRepo.Owner = "other"
+				spec.TemplateRepo.Name = "other-app"
+			},
+			want: []string{"spec.templateRepo.owner", "spec.templateRepo.name"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := newTestStarterKit()
+			skit := old.DeepCopy()
+			tt.modify(&skit.Spec)
+			expectErrorPaths(t, validateImmutableFields(&skit.Spec, &old.Spec), tt.want)
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetRepo string
+		invalid    bool
+		modify     func(skit *StarterKit)
+		valid      bool
+	}{
+		{
+			name:       "target repo renamed before it was created",
+			targetRepo: "",
+			modify:     func(skit *StarterKit) { skit.Spec.TemplateRepo.Name = "other-app" },
+			valid:      true,
+		},
+		{
+			name:       "target repo renamed to the one of another StarterKit before it was created",
+			targetRepo: "",
+			modify:     func(skit *StarterKit) { skit.Spec.TemplateRepo.Name = "taken-app" },
+			valid:      false,
+		},
+		{
+			name:       "target repo renamed after it was created",
+			targetRepo: "https://github.com/devx-test/my-app",
+			modify:     func(skit *StarterKit) { skit.Spec.TemplateRepo.Name = "other-app" },
+			valid:      false,
+		},
+		{
+			name:       "finalizer added to an invalid StarterKit",
+			targetRepo: "https://github.com/devx-test/my-app",
+			invalid:    true,
+			modify:     func(skit *StarterKit) { skit.Finalizers = []string{"devx.ibm.com/finalizer"} },
+			valid:      true,
+		},
+		{
+			name:       "invalid StarterKit changed",
+			targetRepo: "https://github.com/devx-test/my-app",
+			invalid:    true,
+			modify:     func(skit *StarterKit) { skit.Spec.Options.Port = 3000 },
+			valid:      false,
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	other := newTestStarterKit()
+	other.Name = "taken-app"
+	other.Spec.TemplateRepo.Name = "taken-app"
+	validator := &starterKitValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(other).Build()}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := newTestStarterKit()
+			old.Status.TargetRepo = tt.targetRepo
+			if tt.invalid {
+				// StarterKits created before the webhook may not pass the validation
+				old.Spec.TemplateRepo.SecretKeyRef = corev1.SecretKeySelector{}
+			}
+			skit := old.DeepCopy()
+			tt.modify(skit)
+			err := validator.ValidateUpdate(context.Background(), old, skit)
+			if valid := err == nil; valid != tt.valid {
+				t.Errorf("ValidateUpdate() = %v, expected valid to be %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateTargetRepo(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	other := newTestStarterKit()
+	other.Name = "other-app"
+	other.Namespace = "other"
+
+	tests := []struct {
+		name   string
+		modify func(skit *StarterKit)
+		valid  bool
+	}{
+		{
+			name:   "same target repo",
+			modify: func(skit *StarterKit) {},
+			valid:  false,
+		},
+		{
+			name:   "same target repo in another case",
+			modify: func(skit *StarterKit) { skit.Spec.TemplateRepo.Name = "My-App" },
+			valid:  false,
+		},
+		{
+			name:   "other target repo",
+			modify: func(skit *StarterKit) { skit.Spec.TemplateRepo.Name = "my-other-app" },
+			valid:  true,
+		},
+		{
+			name: "same StarterKit",
+			modify: func(skit *StarterKit) {
+				skit.Name = other.Name
+				skit.Namespace = other.Namespace
+			},
+			valid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &starterKitValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(other.DeepCopy()).Build()}
+			skit := newTestStarterKit()
+			tt.modify(skit)
+			allErrs, err := v.validateTargetRepo(context.Background(), skit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if valid := len(allErrs) == 0; valid != tt.valid {
+				t.Errorf("validateTargetRepo() = %v, expected valid to be %v", allErrs, tt.valid)
+			}
+		})
+	}
+}
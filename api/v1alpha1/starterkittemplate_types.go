@@ -0,0 +1,93 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StarterKitTemplateSpec defines the desired state of StarterKitTemplate
+type StarterKitTemplateSpec struct {
+	// TemplateOwner is the owner of the GitHub template repo
+	TemplateOwner string `json:"templateOwner"`
+	// TemplateRepoName is the name of the GitHub template repo
+	TemplateRepoName string `json:"templateRepoName"`
+	// Description describes the application created from the template
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Icon is the URL of an icon representing the template
+	// +optional
+	Icon string `json:"icon,omitempty"`
+	// Port is the default port of the application
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// Env are the default environment variables of the application
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// BuildStrategy is the default strategy the application image is built with
+	// +optional
+	BuildStrategy *StarterKitSpecBuildStrategy `json:"buildStrategy,omitempty"`
+}
+
+// CatalogLabel marks the StarterKitTemplates managed by the catalog syncer
+const CatalogLabel = "devx.ibm.com/catalog"
+
+// StarterKitTemplateStatus defines the observed state of StarterKitTemplate
+type StarterKitTemplateStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Deprecated is set by the catalog syncer when the template repo is no longer found in the catalog
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
+	// SyncTimestamp is the time the catalog syncer last found the template repo
+	// +optional
+	SyncTimestamp *metav1.Time `json:"syncTimestamp,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.templateOwner`
+// +kubebuilder:printcolumn:name="Repo",type=string,JSONPath=`.spec.templateRepoName`
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=`.spec.description`
+// +kubebuilder:printcolumn:name="Deprecated",type=boolean,JSONPath=`.status.deprecated`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// StarterKitTemplate is the Schema for the starterkittemplates API. It describes a GitHub template repo StarterKits
+// can be created from by name.
+type StarterKitTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StarterKitTemplateSpec   `json:"spec,omitempty"`
+	Status StarterKitTemplateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StarterKitTemplateList contains a list of StarterKitTemplate
+type StarterKitTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StarterKitTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StarterKitTemplate{}, &StarterKitTemplateList{})
+}
@@ -22,7 +22,9 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -31,7 +33,7 @@ func (in *StarterKit) DeepCopyInto(out *StarterKit) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKit.
@@ -84,11 +86,94 @@ func (in *StarterKitList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitManifest) DeepCopyInto(out *StarterKitManifest) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BuildStrategy != nil {
+		in, out := &in.BuildStrategy, &out.BuildStrategy
+		*out = new(StarterKitSpecBuildStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bindings != nil {
+		in, out := &in.Bindings, &out.Bindings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ParameterFiles != nil {
+		in, out := &in.ParameterFiles, &out.ParameterFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitManifest.
+func (in *StarterKitManifest) DeepCopy() *StarterKitManifest {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitManifest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StarterKitSpec) DeepCopyInto(out *StarterKitSpec) {
 	*out = *in
 	in.Options.DeepCopyInto(&out.Options)
 	in.TemplateRepo.DeepCopyInto(&out.TemplateRepo)
+	in.Route.DeepCopyInto(&out.Route)
+	in.Knative.DeepCopyInto(&out.Knative)
+	in.Exposure.DeepCopyInto(&out.Exposure)
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(StarterKitSpecNetworkPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Webhook.DeepCopyInto(&out.Webhook)
+	in.Triggers.DeepCopyInto(&out.Triggers)
+	out.Previews = in.Previews
+	out.Reporting = in.Reporting
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make([]StarterKitSpecEnvironment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Bindings != nil {
+		in, out := &in.Bindings, &out.Bindings
+		*out = make([]StarterKitSpecBinding, len(*in))
+		copy(*out, *in)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]StarterKitSpecService, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceBindings != nil {
+		in, out := &in.ServiceBindings, &out.ServiceBindings
+		*out = make([]StarterKitSpecServiceBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpec.
@@ -101,6 +186,203 @@ func (in *StarterKitSpec) DeepCopy() *StarterKitSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecBinding) DeepCopyInto(out *StarterKitSpecBinding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecBinding.
+func (in *StarterKitSpecBinding) DeepCopy() *StarterKitSpecBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecBuildStrategy) DeepCopyInto(out *StarterKitSpecBuildStrategy) {
+	*out = *in
+	if in.BuilderImage != nil {
+		in, out := &in.BuilderImage, &out.BuilderImage
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecBuildStrategy.
+func (in *StarterKitSpecBuildStrategy) DeepCopy() *StarterKitSpecBuildStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecBuildStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecEnvironment) DeepCopyInto(out *StarterKitSpecEnvironment) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecEnvironment.
+func (in *StarterKitSpecEnvironment) DeepCopy() *StarterKitSpecEnvironment {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecEnvironment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecExposure) DeepCopyInto(out *StarterKitSpecExposure) {
+	*out = *in
+	if in.GatewayAPI != nil {
+		in, out := &in.GatewayAPI, &out.GatewayAPI
+		*out = new(StarterKitSpecGatewayAPI)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecExposure.
+func (in *StarterKitSpecExposure) DeepCopy() *StarterKitSpecExposure {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecExposure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecGatewayAPI) DeepCopyInto(out *StarterKitSpecGatewayAPI) {
+	*out = *in
+	out.GatewayRef = in.GatewayRef
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecGatewayAPI.
+func (in *StarterKitSpecGatewayAPI) DeepCopy() *StarterKitSpecGatewayAPI {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecGatewayAPI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecGatewayRef) DeepCopyInto(out *StarterKitSpecGatewayRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecGatewayRef.
+func (in *StarterKitSpecGatewayRef) DeepCopy() *StarterKitSpecGatewayRef {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecGatewayRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecIssuerRef) DeepCopyInto(out *StarterKitSpecIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecIssuerRef.
+func (in *StarterKitSpecIssuerRef) DeepCopy() *StarterKitSpecIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecKnative) DeepCopyInto(out *StarterKitSpecKnative) {
+	*out = *in
+	if in.ContainerConcurrency != nil {
+		in, out := &in.ContainerConcurrency, &out.ContainerConcurrency
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinScale != nil {
+		in, out := &in.MinScale, &out.MinScale
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxScale != nil {
+		in, out := &in.MaxScale, &out.MaxScale
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecKnative.
+func (in *StarterKitSpecKnative) DeepCopy() *StarterKitSpecKnative {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecKnative)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecNetworkPolicy) DeepCopyInto(out *StarterKitSpecNetworkPolicy) {
+	*out = *in
+	if in.RouterNamespaceSelector != nil {
+		in, out := &in.RouterNamespaceSelector, &out.RouterNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = make([]networkingv1.NetworkPolicyEgressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecNetworkPolicy.
+func (in *StarterKitSpecNetworkPolicy) DeepCopy() *StarterKitSpecNetworkPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecNetworkPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StarterKitSpecOptions) DeepCopyInto(out *StarterKitSpecOptions) {
 	*out = *in
@@ -111,6 +393,26 @@ func (in *StarterKitSpecOptions) DeepCopyInto(out *StarterKitSpecOptions) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]StarterKitSpecPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.BuildStrategy != nil {
+		in, out := &in.BuildStrategy, &out.BuildStrategy
+		*out = new(StarterKitSpecBuildStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecOptions.
@@ -123,10 +425,144 @@ func (in *StarterKitSpecOptions) DeepCopy() *StarterKitSpecOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecPort) DeepCopyInto(out *StarterKitSpecPort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecPort.
+func (in *StarterKitSpecPort) DeepCopy() *StarterKitSpecPort {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecPreviews) DeepCopyInto(out *StarterKitSpecPreviews) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecPreviews.
+func (in *StarterKitSpecPreviews) DeepCopy() *StarterKitSpecPreviews {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecPreviews)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecReporting) DeepCopyInto(out *StarterKitSpecReporting) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecReporting.
+func (in *StarterKitSpecReporting) DeepCopy() *StarterKitSpecReporting {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecReporting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecRoute) DeepCopyInto(out *StarterKitSpecRoute) {
+	*out = *in
+	if in.CertificateSecretRef != nil {
+		in, out := &in.CertificateSecretRef, &out.CertificateSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(StarterKitSpecIssuerRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecRoute.
+func (in *StarterKitSpecRoute) DeepCopy() *StarterKitSpecRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecService) DeepCopyInto(out *StarterKitSpecService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecService.
+func (in *StarterKitSpecService) DeepCopy() *StarterKitSpecService {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecServiceBinding) DeepCopyInto(out *StarterKitSpecServiceBinding) {
+	*out = *in
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(StarterKitSpecServiceReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecServiceBinding.
+func (in *StarterKitSpecServiceBinding) DeepCopy() *StarterKitSpecServiceBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecServiceBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecServiceReference) DeepCopyInto(out *StarterKitSpecServiceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecServiceReference.
+func (in *StarterKitSpecServiceReference) DeepCopy() *StarterKitSpecServiceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecServiceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StarterKitSpecTemplate) DeepCopyInto(out *StarterKitSpecTemplate) {
 	*out = *in
 	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecTemplate.
@@ -139,9 +575,114 @@ func (in *StarterKitSpecTemplate) DeepCopy() *StarterKitSpecTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecTriggers) DeepCopyInto(out *StarterKitSpecTriggers) {
+	*out = *in
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecTriggers.
+func (in *StarterKitSpecTriggers) DeepCopy() *StarterKitSpecTriggers {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecTriggers)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitSpecWebhook) DeepCopyInto(out *StarterKitSpecWebhook) {
+	*out = *in
+	if in.SecretRotationInterval != nil {
+		in, out := &in.SecretRotationInterval, &out.SecretRotationInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitSpecWebhook.
+func (in *StarterKitSpecWebhook) DeepCopy() *StarterKitSpecWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitSpecWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StarterKitStatus) DeepCopyInto(out *StarterKitStatus) {
 	*out = *in
+	if in.Manifest != nil {
+		in, out := &in.Manifest, &out.Manifest
+		*out = new(StarterKitManifest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make([]StarterKitStatusEnvironment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PullRequests != nil {
+		in, out := &in.PullRequests, &out.PullRequests
+		*out = make([]StarterKitStatusPullRequest, len(*in))
+		copy(*out, *in)
+	}
+	if in.WebhookEvents != nil {
+		in, out := &in.WebhookEvents, &out.WebhookEvents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WebhookSecretRotationTimestamp != nil {
+		in, out := &in.WebhookSecretRotationTimestamp, &out.WebhookSecretRotationTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]StarterKitStatusService, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceBindings != nil {
+		in, out := &in.ServiceBindings, &out.ServiceBindings
+		*out = make([]StarterKitStatusServiceBinding, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bindings != nil {
+		in, out := &in.Bindings, &out.Bindings
+		*out = make([]StarterKitStatusBinding, len(*in))
+		copy(*out, *in)
+	}
+	if in.LatestBuild != nil {
+		in, out := &in.LatestBuild, &out.LatestBuild
+		*out = new(StarterKitStatusBuild)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageHistory != nil {
+		in, out := &in.ImageHistory, &out.ImageHistory
+		*out = make([]StarterKitStatusImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitStatus.
@@ -153,3 +694,226 @@ func (in *StarterKitStatus) DeepCopy() *StarterKitStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitStatusBinding) DeepCopyInto(out *StarterKitStatusBinding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitStatusBinding.
+func (in *StarterKitStatusBinding) DeepCopy() *StarterKitStatusBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitStatusBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitStatusBuild) DeepCopyInto(out *StarterKitStatusBuild) {
+	*out = *in
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTimestamp != nil {
+		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitStatusBuild.
+func (in *StarterKitStatusBuild) DeepCopy() *StarterKitStatusBuild {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitStatusBuild)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitStatusEnvironment) DeepCopyInto(out *StarterKitStatusEnvironment) {
+	*out = *in
+	if in.PromotionTimestamp != nil {
+		in, out := &in.PromotionTimestamp, &out.PromotionTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitStatusEnvironment.
+func (in *StarterKitStatusEnvironment) DeepCopy() *StarterKitStatusEnvironment {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitStatusEnvironment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitStatusImage) DeepCopyInto(out *StarterKitStatusImage) {
+	*out = *in
+	in.Created.DeepCopyInto(&out.Created)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitStatusImage.
+func (in *StarterKitStatusImage) DeepCopy() *StarterKitStatusImage {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitStatusImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitStatusPullRequest) DeepCopyInto(out *StarterKitStatusPullRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitStatusPullRequest.
+func (in *StarterKitStatusPullRequest) DeepCopy() *StarterKitStatusPullRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitStatusPullRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitStatusService) DeepCopyInto(out *StarterKitStatusService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitStatusService.
+func (in *StarterKitStatusService) DeepCopy() *StarterKitStatusService {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitStatusService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitStatusServiceBinding) DeepCopyInto(out *StarterKitStatusServiceBinding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitStatusServiceBinding.
+func (in *StarterKitStatusServiceBinding) DeepCopy() *StarterKitStatusServiceBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitStatusServiceBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitTemplate) DeepCopyInto(out *StarterKitTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitTemplate.
+func (in *StarterKitTemplate) DeepCopy() *StarterKitTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StarterKitTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitTemplateList) DeepCopyInto(out *StarterKitTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StarterKitTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitTemplateList.
+func (in *StarterKitTemplateList) DeepCopy() *StarterKitTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StarterKitTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitTemplateSpec) DeepCopyInto(out *StarterKitTemplateSpec) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BuildStrategy != nil {
+		in, out := &in.BuildStrategy, &out.BuildStrategy
+		*out = new(StarterKitSpecBuildStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitTemplateSpec.
+func (in *StarterKitTemplateSpec) DeepCopy() *StarterKitTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StarterKitTemplateStatus) DeepCopyInto(out *StarterKitTemplateStatus) {
+	*out = *in
+	if in.SyncTimestamp != nil {
+		in, out := &in.SyncTimestamp, &out.SyncTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StarterKitTemplateStatus.
+func (in *StarterKitTemplateStatus) DeepCopy() *StarterKitTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StarterKitTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultCatalogTopic is the GitHub topic of the starter kit template repos
+const DefaultCatalogTopic = "starter-kit"
+
+// DefaultCatalogSyncInterval is how often the catalog is synced with GitHub
+const DefaultCatalogSyncInterval = time.Hour
+
+// invalidTemplateNameChars matches the characters GitHub repo names may contain but object names may not
+var invalidTemplateNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// CatalogSyncer keeps the StarterKitTemplate catalog in sync with the template repos carrying the configured topics
+// in the configured GitHub orgs. It creates and updates a StarterKitTemplate for every template repo found, and marks
+// the ones whose repo is no longer found as deprecated, unless the search results were incomplete. Templates not
+// created by the syncer are left alone.
+type CatalogSyncer struct {
+	Client       client.Client
+	GitHubClient *github.Client
+	Log          logr.Logger
+	Orgs         []string
+	Topics       []string
+	Interval     time.Duration
+}
+
+// Start syncs the catalog periodically until the context is cancelled. When the GitHub rate limit is exceeded the
+// next sync waits for it to be reset.
+func (s *CatalogSyncer) Start(ctx context.Context) error {
+	s.Log.Info("Starting catalog syncer", "orgs", s.Orgs, "topics", s.Topics, "interval", s.Interval)
+	for {
+		wait := s.Interval
+		if retryAfter, err := s.sync(ctx); err != nil {
+			s.Log.Error(err, "Error syncing catalog")
+			if retryAfter > 0 {
+				wait = retryAfter
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// NeedLeaderElection returns true, since only one operator replica may write the catalog.
+func (s *CatalogSyncer) NeedLeaderElection() bool {
+	return true
+}
+
+// Syncs the catalog once. Returns the time to wait before the next attempt if the sync was aborted by a rate limit.
+func (s *CatalogSyncer) sync(ctx context.Context) (time.Duration, error) {
+	found := map[string]*github.Repository{}
+	incomplete := false
+	for _, org := range s.Orgs {
+		for _, topic := range s.Topics {
+			query := fmt.Sprintf("org:%s topic:%s template:true archived:false", org, topic)
+			opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+			for {
+				result, resp, err := s.GitHubClient.Search.Repositories(ctx, query, opts)
+				if err != nil {
+					return retryAfter(err), err
+				}
+				// GitHub ends searches that time out early, so repos missing from the result may still exist
+				if result.GetIncompleteResults() {
+					incomplete = true
+				}
+				for _, repo := range result.Repositories {
+					if repo.GetIsTemplate() && !repo.GetArchived() {
+						found[templateNameForRepo(repo)] = repo
+					}
+				}
+				if resp.NextPage == 0 {
+					break
+				}
+				opts.Page = resp.NextPage
+				// Stay within the search rate limit instead of running into it
+				if resp.Rate.Remaining == 0 {
+					if err := sleepUntil(ctx, resp.Rate.Reset.Time); err != nil {
+						return 0, err
+					}
+				}
+			}
+		}
+	}
+
+	now := metav1.Now()
+	for name, repo := range found {
+		if err := s.syncTemplate(ctx, name, repo, now); err != nil {
+			return 0, err
+		}
+	}
+
+	// Templates of repos that were not found any more
+	if incomplete {
+		s.Log.Info("Catalog search results are incomplete, skipping deprecation of templates that were not found", "templates", len(found))
+		return 0, nil
+	}
+	templates := &devxv1alpha1.StarterKitTemplateList{}
+	if err := s.Client.List(ctx, templates, client.HasLabels{devxv1alpha1.CatalogLabel}); err != nil {
+		return 0, err
+	}
+	for i := range templates.Items {
+		template := &templates.Items[i]
+		if _, ok := found[template.Name]; ok || template.Status.Deprecated {
+			continue
+		}
+		s.Log.Info("Deprecating StarterKitTemplate", "StarterKitTemplate.Name", template.Name)
+		template.Status.Deprecated = true
+		if err := s.Client.Status().Update(ctx, template); err != nil {
+			return 0, err
+		}
+	}
+	s.Log.Info("Catalog synced successfully", "templates", len(found))
+	return 0, nil
+}
+
+// Creates or updates the StarterKitTemplate of the specified template repo and records that it was found.
+func (s *CatalogSyncer) syncTemplate(ctx context.Context, name string, repo *github.Repository, now metav1.Time) error {
+	desired := devxv1alpha1.StarterKitTemplateSpec{
+		TemplateOwner:    repo.GetOwner().GetLogin(),
+		TemplateRepoName: repo.GetName(),
+		Description:      repo.GetDescription(),
+		Icon:             repo.GetOwner().GetAvatarURL(),
+	}
+
+	template := &devxv1alpha1.StarterKitTemplate{}
+	err := s.Client.Get(ctx, types.NamespacedName{Name: name}, template)
+	if err != nil && errors.IsNotFound(err) {
+		template = &devxv1alpha1.StarterKitTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					devxv1alpha1.CatalogLabel: "",
+				},
+			},
+			Spec: desired,
+		}
+		s.Log.Info("Creating a new StarterKitTemplate", "StarterKitTemplate.Name", name)
+		if err := s.Client.Create(ctx, template); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else if _, managed := template.Labels[devxv1alpha1.CatalogLabel]; !managed {
+		s.Log.Info("Skip sync: StarterKitTemplate is not managed by the catalog", "StarterKitTemplate.Name", name)
+		return nil
+	} else if template.Spec.TemplateOwner != desired.TemplateOwner || template.Spec.TemplateRepoName != desired.TemplateRepoName ||
+		template.Spec.Description != desired.Description || template.Spec.Icon != desired.Icon {
+		// Defaults added to the template by administrators are kept
+		template.Spec.TemplateOwner = desired.TemplateOwner
+		template.Spec.TemplateRepoName = desired.TemplateRepoName
+		template.Spec.Description = desired.Description
+		template.Spec.Icon = desired.Icon
+		s.Log.Info("Updating StarterKitTemplate", "StarterKitTemplate.Name", name)
+		if err := s.Client.Update(ctx, template); err != nil {
+			return err
+		}
+	}
+
+	template.Status.Deprecated = false
+	template.Status.SyncTimestamp = &now
+	return s.Client.Status().Update(ctx, template)
+}
+
+// Returns the name of the StarterKitTemplate of the specified template repo.
+func templateNameForRepo(repo *github.Repository) string {
+	name := strings.ToLower(repo.GetOwner().GetLogin() + "-" + repo.GetName())
+	name = strings.Trim(invalidTemplateNameChars.ReplaceAllString(name, "-"), "-")
+	if len(name) > 253 {
+		name = strings.TrimRight(name[:253], "-")
+	}
+	return name
+}
+
+// Returns how long to wait before calling the GitHub API again after the specified error, or zero if the error is
+// not caused by a rate limit.
+func retryAfter(err error) time.Duration {
+	switch e := err.(type) {
+	case *github.RateLimitError:
+		return time.Until(e.Rate.Reset.Time)
+	case *github.AbuseRateLimitError:
+		if e.RetryAfter != nil {
+			return *e.RetryAfter
+		}
+		return time.Minute
+	}
+	return 0
+}
+
+// Blocks until the specified time or until the context is cancelled.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(t)):
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestTemplateNameForRepo(t *testing.T) {
+	tests := []struct {
+		owner string
+		name  string
+		want  string
+	}{
+		{owner: "IBM", name: "java-spring-app", want: "ibm-java-spring-app"},
+		{owner: "IBM", name: "node_express.app", want: "ibm-node-express-app"},
+		{owner: "IBM", name: "My.App.", want: "ibm-my-app"},
+		{owner: "IBM", name: strings.Repeat("a", 300), want: "ibm-" + strings.Repeat("a", 249)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &github.Repository{Owner: &github.User{Login: github.String(tt.owner)}, Name: github.String(tt.name)}
+			if got := templateNameForRepo(repo); got != tt.want {
+				t.Errorf("templateNameForRepo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogSync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := devxv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	newTemplate := func(name string, managed bool, description string) *devxv1alpha1.StarterKitTemplate {
+		template := &devxv1alpha1.StarterKitTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       devxv1alpha1.StarterKitTemplateSpec{TemplateOwner: "IBM", Description: description},
+		}
+		if managed {
+			template.Labels = map[string]string{devxv1alpha1.CatalogLabel: ""}
+		}
+		return template
+	}
+
+	tests := []struct {
+		name           string
+		incomplete     bool
+		wantDeprecated bool
+	}{
+		{name: "complete results", wantDeprecated: true},
+		{name: "incomplete results", incomplete: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// GitHub search finding one template repo, and an archived one
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				fmt.Fprintf(rw, `{"total_count":2,"incomplete_results":%v,"items":[
+					{"name":"java-spring-app","owner":{"login":"IBM"},"description":"Spring","is_template":true},
+					{"name":"old-app","owner":{"login":"IBM"},"is_template":true,"archived":true}]}`, tt.incomplete)
+			}))
+			defer server.Close()
+			githubClient := github.NewClient(nil)
+			githubClient.BaseURL, _ = url.Parse(server.URL + "/")
+
+			syncer := &CatalogSyncer{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+					newTemplate("ibm-java-spring-app", true, "Outdated"),
+					newTemplate("ibm-old-app", true, "Old"),
+					newTemplate("ibm-custom-app", false, "Custom"),
+				).Build(),
+				GitHubClient: githubClient,
+				Log:          log.Log,
+				Orgs:         []string{"IBM"},
+				Topics:       []string{DefaultCatalogTopic},
+			}
+			if _, err := syncer.sync(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			get := func(name string) *devxv1alpha1.StarterKitTemplate {
+				template := &devxv1alpha1.StarterKitTemplate{}
+				if err := syncer.Client.Get(context.Background(), types.NamespacedName{Name: name}, template); err != nil {
+					t.Fatal(err)
+				}
+				return template
+			}
+			if found := get("ibm-java-spring-app"); found.Spec.Description != "Spring" || found.Status.Deprecated || found.Status.SyncTimestamp == nil {
+				t.Errorf("found template not synced: %+v", found)
+			}
+			if old := get("ibm-old-app"); old.Status.Deprecated != tt.wantDeprecated {
+				t.Errorf("template of archived repo deprecated = %v, want %v", old.Status.Deprecated, tt.wantDeprecated)
+			}
+			if custom := get("ibm-custom-app"); custom.Status.Deprecated || custom.Spec.Description != "Custom" {
+				t.Errorf("template not managed by the catalog changed: %+v", custom)
+			}
+		})
+	}
+}
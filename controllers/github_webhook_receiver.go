@@ -0,0 +1,459 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GitHubWebhookPath is the path GitHub webhook deliveries are served on
+const GitHubWebhookPath = "/github"
+
+// maxWebhookPayloadSize is the maximum size of a webhook delivery accepted by GitHub
+const maxWebhookPayloadSize = 25 << 20
+
+// GitHubWebhookReceiver serves the GitHub webhooks of all StarterKits in the cluster. Deliveries are mapped to the
+// StarterKit whose target repo sent them, authenticated with the HMAC signature computed from the webhook secret of
+// that StarterKit, and turned into builds of its BuildConfigs according to its triggers.
+type GitHubWebhookReceiver struct {
+	Client      client.Client
+	BuildClient buildv1client.BuildV1Interface
+	Log         logr.Logger
+	BindAddress string
+
+	// githubBaseURL overrides the URL of the GitHub API
+	githubBaseURL *url.URL
+}
+
+// Start runs the webhook HTTP server until the context is cancelled.
+func (w *GitHubWebhookReceiver) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(GitHubWebhookPath, w)
+	server := &http.Server{
+		Addr:              w.BindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			w.Log.Error(err, "Error shutting down GitHub webhook receiver")
+		}
+	}()
+
+	w.Log.Info("Starting GitHub webhook receiver", "address", w.BindAddress)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection returns false, since deliveries are load balanced across all operator replicas.
+func (w *GitHubWebhookReceiver) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP handles a single GitHub webhook delivery.
+func (w *GitHubWebhookReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	eventType := github.WebHookType(req)
+	reqLogger := w.Log.WithValues("event", eventType, "delivery", github.DeliveryID(req))
+
+	if req.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, maxWebhookPayloadSize))
+	if err != nil {
+		http.Error(rw, "error reading payload", http.StatusBadRequest)
+		return
+	}
+
+	// Find the StarterKit of the repository that sent the delivery
+	var delivery struct {
+		Repository struct {
+			HTMLURL string `json:"html_url"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &delivery); err != nil || delivery.Repository.HTMLURL == "" {
+		http.Error(rw, "payload does not reference a repository", http.StatusBadRequest)
+		return
+	}
+	skit, err := w.starterKitForRepo(ctx, delivery.Repository.HTMLURL)
+	if err != nil {
+		reqLogger.Error(err, "Error looking up StarterKit for repository", "repository", delivery.Repository.HTMLURL)
+		http.Error(rw, "error looking up repository", http.StatusInternalServerError)
+		return
+	}
+
+	// Authenticate the delivery. Deliveries from unknown repositories are rejected like those with an invalid
+	// signature, so that the response does not reveal which repositories have a StarterKit.
+	if skit == nil {
+		reqLogger.Info("Rejecting delivery: no StarterKit found for repository", "repository", delivery.Repository.HTMLURL)
+		http.Error(rw, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	reqLogger = reqLogger.WithValues("starterkit", types.NamespacedName{Name: skit.Name, Namespace: skit.Namespace})
+	secrets, err := w.webhookSecrets(ctx, skit)
+	if err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "Error fetching webhook secret")
+		http.Error(rw, "error fetching webhook secret", http.StatusInternalServerError)
+		return
+	}
+	if !validSignature(req.Header.Get(github.SHA256SignatureHeader), body, secrets) {
+		reqLogger.Info("Rejecting delivery with missing or invalid signature")
+		http.Error(rw, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	if skit.Spec.Paused {
+		reqLogger.Info("Ignoring delivery for paused StarterKit")
+		rw.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	payload, err := github.ParseWebHook(eventType, body)
+	if err != nil {
+		http.Error(rw, "unsupported event", http.StatusBadRequest)
+		return
+	}
+	switch event := payload.(type) {
+	case *github.PingEvent:
+		reqLogger.Info("Received ping")
+	case *github.PushEvent:
+		if err := w.handlePush(ctx, skit, event, reqLogger); err != nil {
+			reqLogger.Error(err, "Error handling push")
+			http.Error(rw, "error triggering build", http.StatusInternalServerError)
+			return
+		}
+	case *github.ReleaseEvent:
+		if err := w.handleRelease(ctx, skit, event, reqLogger); err != nil {
+			reqLogger.Error(err, "Error handling release")
+			http.Error(rw, "error triggering build", http.StatusInternalServerError)
+			return
+		}
+	case *github.PullRequestEvent:
+		if err := w.handlePullRequest(ctx, skit, event, reqLogger); err != nil {
+			reqLogger.Error(err, "Error handling pull request")
+			http.Error(rw, "error triggering build", http.StatusInternalServerError)
+			return
+		}
+	default:
+		reqLogger.Info("Ignoring event")
+	}
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+// Triggers a build of the pushed commit. Pushes to the tracked branches are built into the latest image, pushes of
+// tracked git tags into an image tagged with the git tag.
+func (w *GitHubWebhookReceiver) handlePush(ctx context.Context, skit *devxv1alpha1.StarterKit, event *github.PushEvent, reqLogger logr.Logger) error {
+	if event.GetDeleted() {
+		reqLogger.Info("Ignoring deleted ref", "ref", event.GetRef())
+		return nil
+	}
+
+	revision := &buildv1.SourceRevision{
+		Type: buildv1.BuildSourceGit,
+		Git: &buildv1.GitSourceRevision{
+			Commit:  event.GetAfter(),
+			Message: event.GetHeadCommit().GetMessage(),
+			Author: buildv1.SourceControlUser{
+				Name:  event.GetHeadCommit().GetAuthor().GetName(),
+				Email: event.GetHeadCommit().GetAuthor().GetEmail(),
+			},
+			Committer: buildv1.SourceControlUser{
+				Name:  event.GetHeadCommit().GetCommitter().GetName(),
+				Email: event.GetHeadCommit().GetCommitter().GetEmail(),
+			},
+		},
+	}
+	cause := "GitHub push to " + event.GetRef()
+
+	if tag := strings.TrimPrefix(event.GetRef(), "refs/tags/"); tag != event.GetRef() {
+		if !matchesAny(skit.Spec.Triggers.Tags, tag) {
+			reqLogger.Info("Ignoring push of untracked tag", "ref", event.GetRef())
+			return nil
+		}
+		return w.triggerTaggedBuild(ctx, skit, revision, imageTagForRef(tag), cause, reqLogger)
+	}
+
+	build := &buildv1.BuildConfig{}
+	if err := w.Client.Get(ctx, types.NamespacedName{Name: skit.Name, Namespace: skit.Namespace}, build); err != nil {
+		return err
+	}
+	branches := skit.Spec.Triggers.Branches
+	if len(branches) == 0 && build.Spec.Source.Git != nil {
+		branches = []string{build.Spec.Source.Git.Ref}
+	}
+	branch := strings.TrimPrefix(event.GetRef(), "refs/heads/")
+	if branch == event.GetRef() || !matchesAny(branches, branch) {
+		reqLogger.Info("Ignoring push to untracked ref", "ref", event.GetRef())
+		return nil
+	}
+
+	created, err := instantiateBuild(ctx, w.BuildClient, build.Namespace, build.Name, revision, nil, cause)
+	if err != nil {
+		return err
+	}
+	reqLogger.Info("Build triggered", "Build.Name", created.Name, "commit", event.GetAfter())
+	return nil
+}
+
+// Triggers a build of the tag of a published release.
+func (w *GitHubWebhookReceiver) handleRelease(ctx context.Context, skit *devxv1alpha1.StarterKit, event *github.ReleaseEvent, reqLogger logr.Logger) error {
+	if !skit.Spec.Triggers.Releases || event.GetAction() != "published" {
+		reqLogger.Info("Ignoring release event", "action", event.GetAction())
+		return nil
+	}
+	tag := event.GetRelease().GetTagName()
+	commit, err := w.resolveTag(ctx, skit, event.GetRepo(), tag)
+	if err != nil {
+		return err
+	}
+	revision := &buildv1.SourceRevision{
+		Type: buildv1.BuildSourceGit,
+		Git: &buildv1.GitSourceRevision{
+			Commit:  commit,
+			Message: event.GetRelease().GetName(),
+			Author: buildv1.SourceControlUser{
+				Name: event.GetRelease().GetAuthor().GetLogin(),
+			},
+		},
+	}
+	return w.triggerTaggedBuild(ctx, skit, revision, imageTagForRef(tag), "GitHub release "+tag, reqLogger)
+}
+
+// Triggers a build of the head commit of an opened or updated pull request. When previews are enabled, the pull
+// request is recorded on the StarterKit instead, so that a preview environment is created for it.
+func (w *GitHubWebhookReceiver) handlePullRequest(ctx context.Context, skit *devxv1alpha1.StarterKit, event *github.PullRequestEvent, reqLogger logr.Logger) error {
+	if skit.Spec.Previews.Enabled {
+		return w.handlePreview(ctx, skit, event, reqLogger)
+	}
+	switch event.GetAction() {
+	case "opened", "reopened", "synchronize":
+	default:
+		reqLogger.Info("Ignoring pull request event", "action", event.GetAction())
+		return nil
+	}
+	if !skit.Spec.Triggers.PullRequests {
+		reqLogger.Info("Ignoring pull request event", "action", event.GetAction())
+		return nil
+	}
+	pr := event.GetPullRequest()
+	revision := &buildv1.SourceRevision{
+		Type: buildv1.BuildSourceGit,
+		Git: &buildv1.GitSourceRevision{
+			Commit:  pr.GetHead().GetSHA(),
+			Message: pr.GetTitle(),
+			Author: buildv1.SourceControlUser{
+				Name: pr.GetUser().GetLogin(),
+			},
+		},
+	}
+	tag := fmt.Sprintf("pr-%d", event.GetNumber())
+	return w.triggerTaggedBuild(ctx, skit, revision, tag, fmt.Sprintf("GitHub pull request #%d", event.GetNumber()), reqLogger)
+}
+
+// Records opened and updated pull requests on the StarterKit and removes closed ones, and triggers a new build of the
+// preview environment of a pull request when commits are pushed to it. Pull requests that may not be previewed are
+// recorded with the reason they are skipped.
+func (w *GitHubWebhookReceiver) handlePreview(ctx context.Context, skit *devxv1alpha1.StarterKit, event *github.PullRequestEvent, reqLogger logr.Logger) error {
+	number := event.GetNumber()
+	headSHA := event.GetPullRequest().GetHead().GetSHA()
+	skipReason := previewSkipReason(skit, event)
+	var closed bool
+	switch event.GetAction() {
+	case "opened", "reopened", "synchronize", "labeled", "unlabeled":
+	case "closed":
+		closed = true
+	default:
+		reqLogger.Info("Ignoring pull request event", "action", event.GetAction())
+		return nil
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := w.Client.Get(ctx, types.NamespacedName{Name: skit.Name, Namespace: skit.Namespace}, skit); err != nil {
+			return err
+		}
+		var prs []devxv1alpha1.StarterKitStatusPullRequest
+		found := false
+		for _, pr := range skit.Status.PullRequests {
+			if pr.Number == number {
+				found = true
+				if closed {
+					continue
+				}
+				pr.HeadSHA = headSHA
+				pr.SkipReason = skipReason
+			}
+			prs = append(prs, pr)
+		}
+		if !found && !closed {
+			prs = append(prs, devxv1alpha1.StarterKitStatusPullRequest{Number: number, HeadSHA: headSHA, SkipReason: skipReason})
+		}
+		skit.Status.PullRequests = prs
+		return w.Client.Status().Update(ctx, skit)
+	})
+	if err != nil {
+		return err
+	}
+	if closed {
+		reqLogger.Info("Pull request closed, removing preview", "number", number)
+		return nil
+	}
+	if skipReason != "" {
+		reqLogger.Info("Pull request recorded without preview", "number", number, "reason", skipReason)
+		return nil
+	}
+	reqLogger.Info("Pull request recorded for preview", "number", number)
+
+	// A newly created preview BuildConfig builds the pull request on its own
+	if event.GetAction() != "synchronize" {
+		return nil
+	}
+	revision := &buildv1.SourceRevision{
+		Type: buildv1.BuildSourceGit,
+		Git: &buildv1.GitSourceRevision{
+			Commit:  headSHA,
+			Message: event.GetPullRequest().GetTitle(),
+			Author: buildv1.SourceControlUser{
+				Name: event.GetSender().GetLogin(),
+			},
+		},
+	}
+	created, err := instantiateBuild(ctx, w.BuildClient, skit.Namespace, previewName(skit, number), revision, nil, fmt.Sprintf("GitHub pull request #%d", number))
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	reqLogger.Info("Preview build triggered", "Build.Name", created.Name, "commit", headSHA)
+	return nil
+}
+
+// Triggers a build of the specified revision whose image is published as the given ImageStream tag.
+func (w *GitHubWebhookReceiver) triggerTaggedBuild(ctx context.Context, skit *devxv1alpha1.StarterKit, revision *buildv1.SourceRevision, imageTag string, cause string, reqLogger logr.Logger) error {
+	annotations := map[string]string{
+		imageTagAnnotation: imageTag,
+	}
+	created, err := instantiateBuild(ctx, w.BuildClient, skit.Namespace, taggedBuildConfigName(skit), revision, annotations, cause)
+	if err != nil {
+		return err
+	}
+	reqLogger.Info("Tagged build triggered", "Build.Name", created.Name, "commit", revision.Git.Commit, "tag", imageTag)
+	return nil
+}
+
+// Returns the SHA of the commit the specified git tag of the repo points to, looked up with the GitHub token of the
+// StarterKit.
+func (w *GitHubWebhookReceiver) resolveTag(ctx context.Context, skit *devxv1alpha1.StarterKit, repo *github.Repository, tag string) (string, error) {
+	secret := &corev1.Secret{}
+	if err := w.Client.Get(ctx, types.NamespacedName{Name: skit.Spec.TemplateRepo.SecretKeyRef.Name, Namespace: skit.Namespace}, secret); err != nil {
+		return "", err
+	}
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: string(secret.Data[skit.Spec.TemplateRepo.SecretKeyRef.Key])},
+	)
+	githubClient := github.NewClient(oauth2.NewClient(ctx, ts))
+	if w.githubBaseURL != nil {
+		githubClient.BaseURL = w.githubBaseURL
+	}
+	sha, _, err := githubClient.Repositories.GetCommitSHA1(ctx, repo.GetOwner().GetLogin(), repo.GetName(), "tags/"+tag, "")
+	return sha, err
+}
+
+// Returns the StarterKit whose target repo has the specified URL, or nil if there is none.
+func (w *GitHubWebhookReceiver) starterKitForRepo(ctx context.Context, repoURL string) (*devxv1alpha1.StarterKit, error) {
+	skits := &devxv1alpha1.StarterKitList{}
+	if err := w.Client.List(ctx, skits); err != nil {
+		return nil, err
+	}
+	for i := range skits.Items {
+		if strings.EqualFold(skits.Items[i].Status.TargetRepo, repoURL) {
+			return &skits.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Returns the secrets a delivery for the specified StarterKit may be signed with.
+func (w *GitHubWebhookReceiver) webhookSecrets(ctx context.Context, skit *devxv1alpha1.StarterKit) ([][]byte, error) {
+	secret := &corev1.Secret{}
+	if err := w.Client.Get(ctx, types.NamespacedName{Name: skit.Name, Namespace: skit.Namespace}, secret); err != nil {
+		return nil, err
+	}
+	var secrets [][]byte
+	for _, k := range []string{webHookSecretKey, previousWebHookSecretKey} {
+		if key := secret.Data[k]; len(key) > 0 {
+			secrets = append(secrets, key)
+		}
+	}
+	return secrets, nil
+}
+
+// Returns true if the signature of the payload was computed with one of the specified secrets.
+func validSignature(signature string, payload []byte, secrets [][]byte) bool {
+	for _, secret := range secrets {
+		if github.ValidateSignature(signature, payload, secret) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Starts a new build from the specified BuildConfig. The build uses the given source revision if one is set, carries
+// the given annotations, and records the cause in its trigger information.
+func instantiateBuild(ctx context.Context, buildClient buildv1client.BuildV1Interface, namespace string, name string, revision *buildv1.SourceRevision, annotations map[string]string, cause string) (*buildv1.Build, error) {
+	request := &buildv1.BuildRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Revision: revision,
+		TriggeredBy: []buildv1.BuildTriggerCause{
+			{
+				Message: cause,
+			},
+		},
+	}
+	return buildClient.BuildConfigs(namespace).Instantiate(ctx, name, request, metav1.CreateOptions{})
+}
@@ -0,0 +1,386 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	buildv1 "github.com/openshift/api/build/v1"
+	fakebuildv1 "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const testRepoURL = "https://github.com/octocat/my-app"
+
+// Returns the HMAC signature GitHub computes for the payload with the specified secret.
+func sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Returns a StarterKit whose target repo is testRepoURL.
+func newTestReceiverStarterKit() *devxv1alpha1.StarterKit {
+	return &devxv1alpha1.StarterKit{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "dev"},
+		Status:     devxv1alpha1.StarterKitStatus{TargetRepo: testRepoURL},
+	}
+}
+
+// Returns a webhook receiver backed by fake clients holding the specified objects, and the build requests it made.
+func newTestReceiver(t *testing.T, objs ...client.Object) (*GitHubWebhookReceiver, *[]*buildv1.BuildRequest) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, buildv1.AddToScheme, devxv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	requests := &[]*buildv1.BuildRequest{}
+	buildClient := &fakebuildv1.FakeBuildV1{Fake: &clienttesting.Fake{}}
+	buildClient.PrependReactor("create", "buildconfigs", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		request := action.(clienttesting.CreateAction).GetObject().(*buildv1.BuildRequest)
+		*requests = append(*requests, request)
+		return true, &buildv1.Build{ObjectMeta: metav1.ObjectMeta{Name: request.Name + "-1", Namespace: request.Namespace}}, nil
+	})
+
+	return &GitHubWebhookReceiver{
+		Client:      fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		BuildClient: buildClient,
+		Log:         log.Log,
+	}, requests
+}
+
+func TestValidSignature(t *testing.T) {
+	payload := []byte(`{"zen":"Keep it logically awesome."}`)
+	secrets := [][]byte{[]byte("current"), []byte("previous")}
+
+	tests := []struct {
+		name      string
+		signature string
+		secrets   [][]byte
+		want      bool
+	}{
+		{name: "current secret", signature: sign(payload, "current"), secrets: secrets, want: true},
+		{name: "previous secret", signature: sign(payload, "previous"), secrets: secrets, want: true},
+		{name: "other secret", signature: sign(payload, "other"), secrets: secrets},
+		{name: "other payload", signature: sign([]byte("{}"), "current"), secrets: secrets},
+		{name: "missing signature", signature: "", secrets: secrets},
+		{name: "malformed signature", signature: "sha256=not-hex", secrets: secrets},
+		{name: "no secrets", signature: sign(payload, "current")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validSignature(tt.signature, payload, tt.secrets); got != tt.want {
+				t.Errorf("validSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeHTTPRejectsUnauthenticatedDeliveries(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "dev"},
+		Data:       map[string][]byte{webHookSecretKey: []byte("secret")},
+	}
+	knownPayload := []byte(`{"repository":{"html_url":"` + testRepoURL + `"}}`)
+	unknownPayload := []byte(`{"repository":{"html_url":"https://github.com/octocat/other"}}`)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+	}{
+		{name: "unknown repository", payload: unknownPayload, signature: sign(unknownPayload, "secret")},
+		{name: "invalid signature", payload: knownPayload, signature: sign(knownPayload, "other")},
+		{name: "missing signature", payload: knownPayload},
+	}
+	var responses []string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, requests := newTestReceiver(t, newTestReceiverStarterKit(), secret)
+			req := httptest.NewRequest(http.MethodPost, GitHubWebhookPath, bytes.NewReader(tt.payload))
+			req.Header.Set(github.EventTypeHeader, "push")
+			if tt.signature != "" {
+				req.Header.Set(github.SHA256SignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			receiver.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+			if len(*requests) != 0 {
+				t.Errorf("%d builds triggered, want none", len(*requests))
+			}
+			responses = append(responses, rec.Body.String())
+		})
+	}
+	for _, body := range responses[1:] {
+		if body != responses[0] {
+			t.Errorf("response %q differs from %q", body, responses[0])
+		}
+	}
+}
+
+func TestHandlePush(t *testing.T) {
+	buildConfig := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "dev"},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Source: buildv1.BuildSource{Git: &buildv1.GitBuildSource{URI: testRepoURL, Ref: "master"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		triggers  devxv1alpha1.StarterKitSpecTriggers
+		event     *github.PushEvent
+		wantBuild string
+		wantTag   string
+	}{
+		{
+			name:      "push to followed branch",
+			event:     &github.PushEvent{Ref: github.String("refs/heads/master"), After: github.String("abc")},
+			wantBuild: "my-app",
+		},
+		{
+			name:  "push to other branch",
+			event: &github.PushEvent{Ref: github.String("refs/heads/feature"), After: github.String("abc")},
+		},
+		{
+			name:      "push to tracked branch",
+			triggers:  devxv1alpha1.StarterKitSpecTriggers{Branches: []string{"release/*"}},
+			event:     &github.PushEvent{Ref: github.String("refs/heads/release/1.0"), After: github.String("abc")},
+			wantBuild: "my-app",
+		},
+		{
+			name:  "deleted branch",
+			event: &github.PushEvent{Ref: github.String("refs/heads/master"), Deleted: github.Bool(true)},
+		},
+		{
+			name:      "push of tracked tag",
+			triggers:  devxv1alpha1.StarterKitSpecTriggers{Tags: []string{"v*"}},
+			event:     &github.PushEvent{Ref: github.String("refs/tags/v1.0.0"), After: github.String("abc")},
+			wantBuild: "my-app-tagged",
+			wantTag:   "v1.0.0",
+		},
+		{
+			name:  "push of untracked tag",
+			event: &github.PushEvent{Ref: github.String("refs/tags/v1.0.0"), After: github.String("abc")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skit := newTestReceiverStarterKit()
+			skit.Spec.Triggers = tt.triggers
+			receiver, requests := newTestReceiver(t, skit, buildConfig)
+
+			if err := receiver.handlePush(context.Background(), skit, tt.event, log.Log); err != nil {
+				t.Fatal(err)
+			}
+			expectBuildRequest(t, *requests, tt.wantBuild, tt.event.GetAfter(), tt.wantTag)
+		})
+	}
+}
+
+func TestHandleRelease(t *testing.T) {
+	tests := []struct {
+		name      string
+		releases  bool
+		action    string
+		wantBuild string
+	}{
+		{name: "published release", releases: true, action: "published", wantBuild: "my-app-tagged"},
+		{name: "edited release", releases: true, action: "edited"},
+		{name: "releases not tracked", action: "published"},
+	}
+
+	// GitHub API resolving the release tag to its commit
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/repos/octocat/my-app/commits/tags/v1.0.0" || req.Header.Get("Authorization") != "Bearer token" {
+			http.NotFound(rw, req)
+			return
+		}
+		rw.Write([]byte("abc"))
+	}))
+	defer server.Close()
+	githubBaseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skit := newTestReceiverStarterKit()
+			skit.Spec.TemplateRepo.SecretKeyRef = corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "github"},
+				Key:                  "token",
+			}
+			skit.Spec.Triggers.Releases = tt.releases
+			token := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: "dev"},
+				Data:       map[string][]byte{"token": []byte("token")},
+			}
+			receiver, requests := newTestReceiver(t, skit, token)
+			receiver.githubBaseURL = githubBaseURL
+			event := &github.ReleaseEvent{
+				Action:  github.String(tt.action),
+				Release: &github.RepositoryRelease{TagName: github.String("v1.0.0")},
+				Repo: &github.Repository{
+					Owner: &github.User{Login: github.String("octocat")},
+					Name:  github.String("my-app"),
+				},
+			}
+
+			if err := receiver.handleRelease(context.Background(), skit, event, log.Log); err != nil {
+				t.Fatal(err)
+			}
+			expectBuildRequest(t, *requests, tt.wantBuild, "abc", "v1.0.0")
+		})
+	}
+}
+
+func TestHandlePullRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		pullRequests bool
+		action       string
+		wantBuild    string
+	}{
+		{name: "opened", pullRequests: true, action: "opened", wantBuild: "my-app-tagged"},
+		{name: "synchronized", pullRequests: true, action: "synchronize", wantBuild: "my-app-tagged"},
+		{name: "closed", pullRequests: true, action: "closed"},
+		{name: "pull requests not tracked", action: "opened"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skit := newTestReceiverStarterKit()
+			skit.Spec.Triggers.PullRequests = tt.pullRequests
+			receiver, requests := newTestReceiver(t, skit)
+			event := &github.PullRequestEvent{
+				Action: github.String(tt.action),
+				Number: github.Int(7),
+				PullRequest: &github.PullRequest{
+					Head: &github.PullRequestBranch{SHA: github.String("abc")},
+				},
+			}
+
+			if err := receiver.handlePullRequest(context.Background(), skit, event, log.Log); err != nil {
+				t.Fatal(err)
+			}
+			expectBuildRequest(t, *requests, tt.wantBuild, "abc", "pr-7")
+		})
+	}
+}
+
+// Fails the test unless the requests hold a single build of the named BuildConfig from the given commit, published
+// under the given image tag, or no build at all if the BuildConfig name is empty.
+func expectBuildRequest(t *testing.T, requests []*buildv1.BuildRequest, name string, commit string, imageTag string) {
+	t.Helper()
+	if name == "" {
+		if len(requests) != 0 {
+			t.Errorf("%d builds triggered, want none", len(requests))
+		}
+		return
+	}
+	if len(requests) != 1 {
+		t.Fatalf("%d builds triggered, want 1", len(requests))
+	}
+	request := requests[0]
+	if request.Name != name {
+		t.Errorf("build of %q triggered, want %q", request.Name, name)
+	}
+	if request.Revision == nil || request.Revision.Git == nil || request.Revision.Git.Commit != commit {
+		t.Errorf("build revision = %+v, want commit %q", request.Revision, commit)
+	}
+	if got := request.Annotations[imageTagAnnotation]; got != imageTag {
+		t.Errorf("image tag = %q, want %q", got, imageTag)
+	}
+}
+
+func TestHandlePreview(t *testing.T) {
+	fork := &github.Repository{FullName: github.String("someone/my-app")}
+	origin := &github.Repository{FullName: github.String("octocat/my-app")}
+
+	tests := []struct {
+		name        string
+		action      string
+		head        *github.Repository
+		association string
+		labels      []string
+		wantSkipped bool
+		wantBuild   string
+	}{
+		{name: "branch of target repo", action: "synchronize", head: origin, association: "CONTRIBUTOR", wantBuild: "my-app-pr-7"},
+		{name: "fork of collaborator", action: "synchronize", head: fork, association: "COLLABORATOR", wantBuild: "my-app-pr-7"},
+		{name: "fork of member", action: "opened", head: fork, association: "MEMBER"},
+		{name: "fork of contributor", action: "synchronize", head: fork, association: "CONTRIBUTOR", wantSkipped: true},
+		{name: "fork of first time contributor", action: "opened", head: fork, association: "FIRST_TIME_CONTRIBUTOR", wantSkipped: true},
+		{name: "labeled fork", action: "labeled", head: fork, association: "NONE", labels: []string{"preview"}},
+		{name: "fork with other label", action: "labeled", head: fork, association: "NONE", labels: []string{"bug"}, wantSkipped: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skit := newTestReceiverStarterKit()
+			skit.Spec.Previews.Enabled = true
+			receiver, requests := newTestReceiver(t, skit)
+			pr := &github.PullRequest{
+				Head:              &github.PullRequestBranch{SHA: github.String("abc"), Repo: tt.head},
+				AuthorAssociation: github.String(tt.association),
+			}
+			for _, label := range tt.labels {
+				pr.Labels = append(pr.Labels, &github.Label{Name: github.String(label)})
+			}
+			event := &github.PullRequestEvent{
+				Action:      github.String(tt.action),
+				Number:      github.Int(7),
+				PullRequest: pr,
+				Repo:        origin,
+			}
+
+			if err := receiver.handlePullRequest(context.Background(), skit, event, log.Log); err != nil {
+				t.Fatal(err)
+			}
+			if len(skit.Status.PullRequests) != 1 {
+				t.Fatalf("%d pull requests recorded, want 1", len(skit.Status.PullRequests))
+			}
+			if skipped := skit.Status.PullRequests[0].SkipReason != ""; skipped != tt.wantSkipped {
+				t.Errorf("skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+			expectBuildRequest(t, *requests, tt.wantBuild, "abc", "")
+		})
+	}
+}
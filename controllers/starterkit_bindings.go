@@ -0,0 +1,162 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// bindingGVK is the IBM Cloud Operator Binding kind
+var bindingGVK = schema.GroupVersionKind{Group: "ibmcloud.ibm.com", Version: "v1alpha1", Kind: "Binding"}
+
+// bindingStateOnline is the state of a Binding whose credentials are available
+const bindingStateOnline = "Online"
+
+// bindingRequeueDelay is how long to wait before checking on Bindings that are not Online yet. Bindings are not
+// watched, as the IBM Cloud Operator may not be installed.
+const bindingRequeueDelay = 15 * time.Second
+
+// bindingVolumePrefix is the prefix of the names of the volumes mounting Binding secrets
+const bindingVolumePrefix = "binding-"
+
+// Returns the IBM Cloud Operator Bindings of the specified StarterKit, including the Bindings of the IBM Cloud
+// services provisioned for it.
+func bindingsForCR(cr *devxv1alpha1.StarterKit) []devxv1alpha1.StarterKitSpecBinding {
+	bindings := append([]devxv1alpha1.StarterKitSpecBinding{}, cr.Spec.Bindings...)
+	for _, svc := range cr.Spec.Services {
+		found := false
+		for _, b := range cr.Spec.Bindings {
+			if b.Name == svc.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			bindings = append(bindings, devxv1alpha1.StarterKitSpecBinding{Name: svc.Name, MountPath: svc.MountPath})
+		}
+	}
+	return bindings
+}
+
+// Looks up the IBM Cloud Operator Bindings of the specified StarterKit, records their state in its status and
+// reports whether all of them are Online.
+func (r *StarterKitReconciler) reconcileBindings(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) (bool, error) {
+	var bindings []devxv1alpha1.StarterKitStatusBinding
+	var pending []string
+	for _, b := range bindingsForCR(instance) {
+		status := devxv1alpha1.StarterKitStatusBinding{Name: b.Name}
+		binding := &unstructured.Unstructured{}
+		binding.SetGroupVersionKind(bindingGVK)
+		err := r.Client.Get(ctx, types.NamespacedName{Name: b.Name, Namespace: instance.Namespace}, binding)
+		if err != nil && (errors.IsNotFound(err) || meta.IsNoMatchError(err)) {
+			pending = append(pending, fmt.Sprintf("%s (not found)", b.Name))
+		} else if err != nil {
+			reqLogger.Error(err, "Error fetching Binding", "Binding.Name", b.Name)
+			return false, err
+		} else {
+			status.State, _, _ = unstructured.NestedString(binding.Object, "status", "state")
+			status.SecretName, _, _ = unstructured.NestedString(binding.Object, "spec", "secretName")
+			if status.SecretName == "" {
+				status.SecretName = b.Name
+			}
+			if status.State != bindingStateOnline {
+				pending = append(pending, fmt.Sprintf("%s (%s)", b.Name, status.State))
+			}
+		}
+		bindings = append(bindings, status)
+	}
+
+	status := instance.Status.DeepCopy()
+	status.Bindings = bindings
+	if len(pending) > 0 {
+		reqLogger.Info("Waiting for Bindings to be Online", "Bindings", pending)
+	}
+	err := r.updateReadyCondition(ctx, instance, status, readyCondition{
+		Type:           devxv1alpha1.ConditionBindingsReady,
+		Reason:         "Online",
+		Message:        "All Bindings are Online",
+		WaitingReason:  "Waiting",
+		WaitingMessage: "Waiting for Bindings",
+	}, len(bindings) == 0, pending)
+	return len(pending) == 0, err
+}
+
+// Injects the credentials of the Bindings of the specified StarterKit into the given container of the pod spec,
+// either as environment variables or as files, and removes the credentials of Bindings that are no longer listed.
+func setBindings(spec *corev1.PodSpec, container string, cr *devxv1alpha1.StarterKit) {
+	secretNames := map[string]string{}
+	for _, b := range cr.Status.Bindings {
+		secretNames[b.Name] = b.SecretName
+	}
+
+	var envFrom []corev1.EnvFromSource
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for _, v := range spec.Volumes {
+		if !strings.HasPrefix(v.Name, bindingVolumePrefix) {
+			volumes = append(volumes, v)
+		}
+	}
+	mode := corev1.SecretVolumeSourceDefaultMode
+	for _, b := range bindingsForCR(cr) {
+		secretName := secretNames[b.Name]
+		if secretName == "" {
+			continue
+		}
+		if b.MountPath == "" {
+			envFrom = append(envFrom, corev1.EnvFromSource{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}},
+			})
+			continue
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: bindingVolumePrefix + b.Name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: secretName, DefaultMode: &mode},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: bindingVolumePrefix + b.Name, MountPath: b.MountPath, ReadOnly: true})
+	}
+
+	spec.Volumes = volumes
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		if c.Name != container {
+			continue
+		}
+		var containerMounts []corev1.VolumeMount
+		for _, m := range c.VolumeMounts {
+			if !strings.HasPrefix(m.Name, bindingVolumePrefix) {
+				containerMounts = append(containerMounts, m)
+			}
+		}
+		c.VolumeMounts = append(containerMounts, mounts...)
+		c.EnvFrom = envFrom
+	}
+}
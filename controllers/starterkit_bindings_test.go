@@ -0,0 +1,107 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxBindingNameLength is the maximum length of binding and service names enforced by the CRD, which keeps the names
+// of the volumes mounting the Binding secrets valid
+const maxBindingNameLength = 55
+
+func TestBindingsForCR(t *testing.T) {
+	cr := &devxv1alpha1.StarterKit{
+		Spec: devxv1alpha1.StarterKitSpec{
+			Bindings: []devxv1alpha1.StarterKitSpecBinding{{Name: "db", MountPath: "/bindings/db"}},
+			Services: []devxv1alpha1.StarterKitSpecService{
+				{Name: "db", ServiceClass: "cloudantnosqldb", Plan: "lite"},
+				{Name: "cache", ServiceClass: "databases-for-redis", Plan: "standard", MountPath: "/bindings/cache"},
+			},
+		},
+	}
+	want := []devxv1alpha1.StarterKitSpecBinding{
+		{Name: "db", MountPath: "/bindings/db"},
+		{Name: "cache", MountPath: "/bindings/cache"},
+	}
+	if got := bindingsForCR(cr); !reflect.DeepEqual(got, want) {
+		t.Errorf("bindingsForCR() = %v, want %v", got, want)
+	}
+}
+
+func TestSetBindings(t *testing.T) {
+	longName := strings.Repeat("a", maxBindingNameLength)
+	cr := &devxv1alpha1.StarterKit{
+		Spec: devxv1alpha1.StarterKitSpec{
+			Bindings: []devxv1alpha1.StarterKitSpecBinding{
+				{Name: "db"},
+				{Name: longName, MountPath: "/bindings/long"},
+				{Name: "pending", MountPath: "/bindings/pending"},
+			},
+		},
+		Status: devxv1alpha1.StarterKitStatus{
+			Bindings: []devxv1alpha1.StarterKitStatusBinding{
+				{Name: "db", State: bindingStateOnline, SecretName: "db-secret"},
+				{Name: longName, State: bindingStateOnline, SecretName: "long-secret"},
+				{Name: "pending", State: "Pending"},
+			},
+		},
+	}
+	spec := &corev1.PodSpec{
+		Volumes: []corev1.Volume{{Name: "config"}, {Name: bindingVolumePrefix + "removed"}},
+		Containers: []corev1.Container{
+			{
+				Name:         "app",
+				VolumeMounts: []corev1.VolumeMount{{Name: "config"}, {Name: bindingVolumePrefix + "removed"}},
+			},
+			{Name: "sidecar"},
+		},
+	}
+
+	setBindings(spec, "app", cr)
+
+	var volumes []string
+	for _, v := range spec.Volumes {
+		volumes = append(volumes, v.Name)
+		if errs := validation.IsDNS1123Label(v.Name); len(errs) > 0 {
+			t.Errorf("invalid volume name %q: %v", v.Name, errs)
+		}
+	}
+	if want := []string{"config", bindingVolumePrefix + longName}; !reflect.DeepEqual(volumes, want) {
+		t.Errorf("volumes = %v, want %v", volumes, want)
+	}
+	app := spec.Containers[0]
+	wantMounts := []corev1.VolumeMount{
+		{Name: "config"},
+		{Name: bindingVolumePrefix + longName, MountPath: "/bindings/long", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(app.VolumeMounts, wantMounts) {
+		t.Errorf("volume mounts = %v, want %v", app.VolumeMounts, wantMounts)
+	}
+	if len(app.EnvFrom) != 1 || app.EnvFrom[0].SecretRef.Name != "db-secret" {
+		t.Errorf("envFrom = %v, want the db-secret Secret", app.EnvFrom)
+	}
+	if sidecar := spec.Containers[1]; len(sidecar.EnvFrom) != 0 || len(sidecar.VolumeMounts) != 0 {
+		t.Errorf("bindings injected into other container: %+v", sidecar)
+	}
+}
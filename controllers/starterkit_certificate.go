@@ -0,0 +1,161 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// certificateGVK is the cert-manager Certificate kind. cert-manager is an optional dependency, so Certificates are
+// handled as unstructured objects rather than through the cert-manager Go types.
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// Returns an empty cert-manager Certificate to fetch objects into.
+func emptyCertificate() *unstructured.Unstructured {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	return certificate
+}
+
+// Returns true if the specified StarterKit requests a certificate from cert-manager.
+func wantsCertificate(cr *devxv1alpha1.StarterKit) bool {
+	return cr.Spec.Route.IssuerRef != nil && cr.Spec.Route.Host != ""
+}
+
+// Returns the name of the Secret holding the Route certificate of the specified StarterKit, or an empty string if
+// the Route does not use a custom certificate.
+func routeCertificateSecretName(cr *devxv1alpha1.StarterKit) string {
+	if cr.Spec.Route.CertificateSecretRef != nil {
+		return cr.Spec.Route.CertificateSecretRef.Name
+	}
+	if wantsCertificate(cr) {
+		return cr.Name + "-tls"
+	}
+	return ""
+}
+
+// Create a new cert-manager Certificate
+func newCertificateForCR(cr *devxv1alpha1.StarterKit) *unstructured.Unstructured {
+	issuerKind := cr.Spec.Route.IssuerRef.Kind
+	if issuerKind == "" {
+		issuerKind = "Issuer"
+	}
+	issuerGroup := cr.Spec.Route.IssuerRef.Group
+	if issuerGroup == "" {
+		issuerGroup = certificateGVK.Group
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	certificate.SetName(cr.Name)
+	certificate.SetNamespace(cr.Namespace)
+	certificate.SetLabels(map[string]string{
+		"app":  cr.Name,
+		"devx": "",
+	})
+	certificate.Object["spec"] = map[string]interface{}{
+		"secretName": routeCertificateSecretName(cr),
+		"dnsNames":   []interface{}{cr.Spec.Route.Host},
+		"issuerRef": map[string]interface{}{
+			"name":  cr.Spec.Route.IssuerRef.Name,
+			"kind":  issuerKind,
+			"group": issuerGroup,
+		},
+	}
+	return certificate
+}
+
+// Creates or updates the cert-manager Certificate of the specified StarterKit and returns the Secret it was issued
+// into. A nil Secret is returned while cert-manager has not issued the certificate yet.
+func (r *StarterKitReconciler) reconcileCertificate(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) (*corev1.Secret, error) {
+	reqLogger.Info("Configuring Certificate")
+	certificate := newCertificateForCR(instance)
+
+	// Set StarterKit instance as the owner and controller
+	if err := controllerutil.SetControllerReference(instance, certificate, r.Scheme); err != nil {
+		reqLogger.Error(err, "Error setting Certificate on StarterKit")
+		return nil, err
+	}
+
+	// Check if this Certificate already exists
+	foundCertificate := &unstructured.Unstructured{}
+	foundCertificate.SetGroupVersionKind(certificateGVK)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: certificate.GetName(), Namespace: certificate.GetNamespace()}, foundCertificate)
+	if err != nil && errors.IsNotFound(err) {
+		reqLogger.Info("Creating a new Certificate", "Certificate.Namespace", certificate.GetNamespace(), "Certificate.Name", certificate.GetName())
+		err = r.Client.Create(ctx, certificate)
+		if err != nil {
+			reqLogger.Error(err, "Error creating Certificate")
+			return nil, err
+		}
+
+		// Certificate created successfully
+		reqLogger.Info("Certificate created successfully")
+	} else if err != nil {
+		reqLogger.Error(err, "Error fetching Certificate")
+		return nil, err
+	} else if !equality.Semantic.DeepEqual(foundCertificate.Object["spec"], certificate.Object["spec"]) {
+		reqLogger.Info("Updating Certificate", "Certificate.Namespace", foundCertificate.GetNamespace(), "Certificate.Name", foundCertificate.GetName())
+		foundCertificate.Object["spec"] = certificate.Object["spec"]
+		err = r.Client.Update(ctx, foundCertificate)
+		if err != nil {
+			reqLogger.Error(err, "Error updating Certificate")
+			return nil, err
+		}
+	}
+
+	// Wait for cert-manager to issue the certificate
+	secret := &corev1.Secret{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: routeCertificateSecretName(instance), Namespace: instance.Namespace}, secret)
+	if err != nil && errors.IsNotFound(err) {
+		reqLogger.Info("Waiting for Certificate to be issued", "Secret.Name", routeCertificateSecretName(instance))
+		return nil, nil
+	} else if err != nil {
+		reqLogger.Error(err, "Error fetching Certificate Secret")
+		return nil, err
+	}
+	return secret, nil
+}
+
+// Maps a Secret to the StarterKits in its namespace whose Route certificate is stored in it, so that Routes are
+// updated when the certificate is renewed.
+func (r *StarterKitReconciler) starterKitsForSecret(obj client.Object) []reconcile.Request {
+	skits := &devxv1alpha1.StarterKitList{}
+	if err := r.Client.List(context.Background(), skits, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "Error listing StarterKits for Secret", "Secret.Namespace", obj.GetNamespace(), "Secret.Name", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, skit := range skits.Items {
+		if routeCertificateSecretName(&skit) == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: skit.Name, Namespace: skit.Namespace}})
+		}
+	}
+	return requests
+}
@@ -18,29 +18,32 @@ package controllers
 
 import (
 	"context"
+	"net/http"
 	"os"
-
-	"github.com/google/go-github/v39/github"
+	"time"
 
 	appsv1 "github.com/openshift/api/apps/v1"
 	buildv1 "github.com/openshift/api/build/v1"
-	configv1 "github.com/openshift/api/config/v1"
 	imagev1 "github.com/openshift/api/image/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
 )
@@ -50,10 +53,19 @@ type StarterKitReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// OperatorNamespace is the namespace the operator and its GitHub webhook receiver run in
+	OperatorNamespace string
+	// BuildClient instantiates BuildConfigs
+	BuildClient buildv1client.BuildV1Interface
+	// AppsClient instantiates DeploymentConfigs
+	AppsClient appsv1client.AppsV1Interface
 }
 
 const starterkitFinalizer = "finalizer.devx.ibm.com"
 
+// certificateRequeueDelay is how long to wait before checking whether cert-manager has issued a requested certificate
+const certificateRequeueDelay = 10 * time.Second
+
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 //
@@ -79,27 +91,57 @@ func (r *StarterKitReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	// Fetch public API URL
-	reqLogger.Info("Fetching k8s API URL")
-	kubernetesAPIURL := &configv1.Infrastructure{}
-	infrastructureName := &types.NamespacedName{
-		Name: "cluster",
+	// Finalize a StarterKit that is being deleted before looking up its template, secret and GitHub repo, so that
+	// none of them can block the deletion. Owned resources are garbage collected.
+	if instance.GetDeletionTimestamp() != nil {
+		if contains(instance.GetFinalizers(), starterkitFinalizer) {
+			// Run finalization logic for starterkitFinalizer. If the
+			// finalization logic fails, don't remove the finalizer so
+			// that we can retry during the next reconciliation.
+			if err := r.finalizeStarterKit(reqLogger, req, instance); err != nil {
+				return reconcile.Result{}, err
+			}
+
+			// Remove starterkitFinalizer. Once all finalizers have been
+			// removed, the object will be deleted.
+			if err := r.removeFinalizer(reqLogger, instance); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, nil
 	}
-	err = r.Client.Get(ctx, *infrastructureName, kubernetesAPIURL)
-	if err != nil {
+
+	// Add finalizer for this CR before creating any resource, in particular the environment resources in other
+	// namespaces, which are not garbage collected with the StarterKit
+	if !contains(instance.GetFinalizers(), starterkitFinalizer) {
+		reqLogger.Info("Adding finalizer to StarterKit")
+		if err := r.addFinalizer(reqLogger, instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Leave the owned resources alone while the StarterKit is paused, even if its template or GitHub secret is missing
+	if err := r.reconcilePause(ctx, instance, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+	if instance.Spec.Paused {
+		reqLogger.Info("Skip reconcile: StarterKit is paused")
+		return reconcile.Result{}, nil
+	}
+
+	// Merge the defaults of the referenced template
+	if err := r.applyTemplate(ctx, instance, reqLogger); err != nil {
 		if errors.IsNotFound(err) {
-			// Request object not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
-			// Return and don't requeue
-			reqLogger.Info("Infrastructure not found")
-			return reconcile.Result{}, nil
+			// The StarterKit is reconciled again once the template is created
+			reqLogger.Info("StarterKitTemplate not found", "StarterKitTemplate.Name", instance.Spec.TemplateRef)
+			return reconcile.Result{}, r.setTemplateFound(ctx, instance, false)
 		}
-		// Error reading the object - requeue the request.
-		reqLogger.Error(err, "Error reading Infrastructure")
+		reqLogger.Error(err, "Error fetching StarterKitTemplate")
+		return reconcile.Result{}, err
+	}
+	if err := r.setTemplateFound(ctx, instance, true); err != nil {
 		return reconcile.Result{}, err
 	}
-	kubernetesAPIURLValue := kubernetesAPIURL.Status.APIServerURL
-	reqLogger.Info("Found Kubernetes public URL", "kubernetesAPIURL", kubernetesAPIURLValue)
 
 	// Fetch GitHub secret
 	githubTokenValue, err := r.fetchGitHubSecret(instance, &req, reqLogger)
@@ -119,6 +161,9 @@ func (r *StarterKitReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// Initialize GitHub Client
 	client := r.getGitHubClient(githubTokenValue, reqLogger)
 
+	// Requeue settings collected while configuring the owned resources
+	result := ctrl.Result{}
+
 	// Read starter kit specification
 	reqLogger.Info("Reading StarterKit specification")
 	err = r.createTargetGitHubRepo(client, instance, reqLogger)
@@ -127,6 +172,17 @@ func (r *StarterKitReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return reconcile.Result{}, err
 	}
 
+	// Merge the defaults of the manifest read from the template repo
+	mergeManifest(&instance.Spec, instance.Status.Manifest)
+
+	// Apply the parameters before anything is built from the target repo
+	if delay, err := r.reconcileParameters(ctx, client, instance, reqLogger); err != nil {
+		reqLogger.Error(err, "Error applying parameters to target GitHub repo")
+		return reconcile.Result{}, err
+	} else if delay > 0 {
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+
 	// Create ImageStream
 	reqLogger.Info("Configuring ImageStream")
 	image := newImageStreamForCR(instance)
@@ -158,66 +214,25 @@ func (r *StarterKitReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		reqLogger.Info("Skip reconcile: Image already exists", "Image.Namespace", foundImage.Namespace, "Image.Name", foundImage.Name)
 	}
 
-	// Create Route
-	reqLogger.Info("Configuring Route")
-	route := newRouteForCR(instance)
-
-	// Set StarterKit instance as the owner and controller
-	if err := controllerutil.SetControllerReference(instance, route, r.Scheme); err != nil {
-		reqLogger.Error(err, "Error setting Route on StarterKit")
-		return reconcile.Result{}, err
-	}
-
-	// Check if this Route already exists
-	foundRoute := &routev1.Route{}
-	err = r.Client.Get(ctx, types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, foundRoute)
-	if err != nil && errors.IsNotFound(err) {
-		reqLogger.Info("Creating a new Route", "Route.Namespace", route.Namespace, "Route.Name", route.Name)
-		err = r.Client.Create(ctx, route)
-		if err != nil {
-			reqLogger.Error(err, "Error creating Route")
+	// Knative exposes the application itself, other runtimes get a Route or HTTPRoute and a Service
+	if instance.Spec.Runtime != devxv1alpha1.RuntimeKnative {
+		if _, err := routeTargetPort(instance); err != nil {
+			reqLogger.Error(err, "Error resolving route port")
 			return reconcile.Result{}, err
 		}
-
-		// Route created successfully
-		reqLogger.Info("Route created successfully")
-	} else if err != nil {
-		reqLogger.Error(err, "Error fetching Route")
-		return reconcile.Result{}, err
-	} else {
-		// Route already exists - don't requeue
-		reqLogger.Info("Skip reconcile: Route already exists", "Route.Namespace", foundRoute.Namespace, "Route.Name", foundRoute.Name)
-	}
-
-	// Create Service
-	reqLogger.Info("Configuring Service")
-	service := newServiceForCR(instance)
-
-	// Set StarterKit instance as the owner and controller
-	if err := controllerutil.SetControllerReference(instance, service, r.Scheme); err != nil {
-		reqLogger.Error(err, "Error setting Service on StarterKit")
-		return reconcile.Result{}, err
-	}
-
-	// Check if this Service already exists
-	foundService := &corev1.Service{}
-	err = r.Client.Get(ctx, types.NamespacedName{Name: service.Name, Namespace: service.Namespace}, foundService)
-	if err != nil && errors.IsNotFound(err) {
-		reqLogger.Info("Creating a new Service", "Service.Namespace", service.Namespace, "Service.Name", service.Name)
-		err = r.Client.Create(ctx, service)
-		if err != nil {
-			reqLogger.Error(err, "Error creating Service")
+		if instance.Spec.Exposure.GatewayAPI != nil {
+			if err := r.reconcileHTTPRoute(ctx, instance, reqLogger); err != nil {
+				return reconcile.Result{}, err
+			}
+		} else if err := r.reconcileRoute(ctx, instance, &result, reqLogger); err != nil {
 			return reconcile.Result{}, err
 		}
-
-		// Service created successfully
-		reqLogger.Info("Service created successfully")
-	} else if err != nil {
-		reqLogger.Error(err, "Error fetching Service")
+		if err := r.reconcileService(ctx, instance, reqLogger); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if err := r.deleteUnusedRuntime(ctx, instance, reqLogger); err != nil {
 		return reconcile.Result{}, err
-	} else {
-		// Service already exists - don't requeue
-		reqLogger.Info("Skip reconcile: Service already exists", "Service.Namespace", foundService.Namespace, "Service.Name", foundService.Name)
 	}
 
 	// Create Secret
@@ -228,6 +243,7 @@ func (r *StarterKitReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return reconcile.Result{}, err
 	}
 	secret := newSecretForCR(instance, token)
+	webhookSecret := token
 
 	// Set StarterKit instance as the owner and controller
 	if err := controllerutil.SetControllerReference(instance, secret, r.Scheme); err != nil {
@@ -254,6 +270,7 @@ func (r *StarterKitReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	} else {
 		// Secret already exists - don't requeue
 		reqLogger.Info("Skip reconcile: Secret already exists", "Secret.Namespace", foundSecret.Namespace, "Secret.Name", foundSecret.Name)
+		webhookSecret = string(foundSecret.Data[webHookSecretKey])
 	}
 
 	// Create BuildConfig
@@ -279,45 +296,259 @@ func (r *StarterKitReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 		// Build created successfully
 		reqLogger.Info("Build created successfully")
+	} else if err != nil {
+		reqLogger.Error(err, "Error fetching Build")
+		return reconcile.Result{}, err
+	} else {
+		// Build already exists - don't requeue
+		reqLogger.Info("Skip reconcile: Build already exists", "Build.Namespace", foundBuild.Namespace, "Build.Name", foundBuild.Name)
+	}
 
-		// Create webhook
-		cfg := config.GetConfigOrDie()
-		cfg.Host = kubernetesAPIURLValue
-		cfg.APIPath = "/apis"
-		cfg.ContentConfig.GroupVersion = &buildv1.SchemeGroupVersion
-		cfg.ContentConfig.NegotiatedSerializer = &serializer.WithoutConversionCodecFactory{CodecFactory: scheme.Codecs}
-		rc, err := rest.RESTClientFor(cfg)
-		if err != nil {
+	// Create BuildConfig for git tags, releases and pull requests
+	if err := r.reconcileTaggedBuildConfig(ctx, instance, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.reconcileImageStreamTags(ctx, instance, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Create webhook
+	if err := r.reconcileGitHubHook(ctx, client, instance, webhookSecret, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.reconcileGitHubHookEvents(ctx, client, instance, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Deploy pull request previews
+	if err := r.reconcilePreviews(ctx, client, instance, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Rotate webhook secret
+	nextRotation, err := r.reconcileWebhookSecretRotation(ctx, client, instance, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	requeueAfter(&result, nextRotation)
+
+	// Restrict the application traffic
+	if err := r.reconcileNetworkPolicy(ctx, instance, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Deploy the application with the selected runtime once its services, Bindings and binding secrets are available
+	servicesReady, err := r.reconcileServices(ctx, instance, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	bindingsReady, err := r.reconcileBindings(ctx, instance, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	serviceBindingsReady, err := r.reconcileServiceBindings(ctx, instance, &result, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !servicesReady || !bindingsReady || !serviceBindingsReady {
+		requeueAfter(&result, bindingRequeueDelay)
+	} else if instance.Spec.Runtime == devxv1alpha1.RuntimeKnative {
+		if err := r.reconcileKnativeService(ctx, instance, &result, reqLogger); err != nil {
 			return reconcile.Result{}, err
 		}
-		hooks := rc.Get().Namespace(build.Namespace).Resource("buildConfigs").Name(build.Name).SubResource("webhooks")
-		githubHook, err := hooks.Suffix(string(secret.Data["WebHookSecretKey"]), "github").URL(), nil
-		if err != nil {
+	} else {
+		if err := r.reconcileDeployment(ctx, instance, reqLogger); err != nil {
 			return reconcile.Result{}, err
 		}
-		reqLogger.Info("Generated Webhook", "Webhook", githubHook)
-
-		hook := github.Hook{
-			Config: map[string]interface{}{
-				"content_type": "json",
-				"url":          githubHook.String(),
-			},
-			Events: []string{"push"},
+	}
+
+	// Rebuild and redeploy on request
+	if err := r.reconcileManualTriggers(ctx, instance, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Publish the state of the owned resources
+	if err := r.updateStatus(ctx, instance, reqLogger); err != nil {
+		reqLogger.Error(err, "Error updating StarterKit status")
+		return reconcile.Result{}, err
+	}
+
+	// Promote the image through the environments
+	if err := r.reconcileEnvironments(ctx, instance, &result, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Report build and rollout results back to GitHub. StarterKits being deleted never get here, so reporting cannot
+	// hold up their deletion.
+	reportErr := r.reconcileCommitStatuses(ctx, client, instance, reqLogger)
+	if err := r.setReportedCondition(ctx, instance, devxv1alpha1.ConditionCommitStatusesReported, instance.Spec.Reporting.CommitStatuses, reportErr, &result); err != nil {
+		return reconcile.Result{}, err
+	}
+	reportErr = r.reconcileGitHubDeployments(ctx, client, instance, reqLogger)
+	deploymentsReported := instance.Spec.Reporting.Deployments && instance.Spec.Runtime != devxv1alpha1.RuntimeKnative
+	if err := r.setReportedCondition(ctx, instance, devxv1alpha1.ConditionDeploymentsReported, deploymentsReported, reportErr, &result); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return result, nil
+}
+
+// Makes the specified result requeue the request after the given delay, unless it already requeues it earlier. A zero
+// delay leaves the result unchanged.
+func requeueAfter(result *ctrl.Result, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+	if result.RequeueAfter == 0 || delay < result.RequeueAfter {
+		result.RequeueAfter = delay
+	}
+}
+
+// Deletes the object named after the specified StarterKit if the StarterKit controls it. Objects that do not exist,
+// or whose kind is not installed, are skipped.
+func (r *StarterKitReconciler) deleteOwned(ctx context.Context, instance *devxv1alpha1.StarterKit, obj client.Object, reqLogger logr.Logger) error {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return err
+	}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, obj)
+	if err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
 		}
+		reqLogger.Error(err, "Error fetching "+gvk.Kind)
+		return err
+	}
+	if !metav1.IsControlledBy(obj, instance) {
+		return nil
+	}
+	reqLogger.Info("Deleting unused "+gvk.Kind, gvk.Kind+".Namespace", obj.GetNamespace(), gvk.Kind+".Name", obj.GetName())
+	if err := r.Client.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "Error deleting "+gvk.Kind)
+		return err
+	}
+	return nil
+}
+
+// Creates or updates the Route exposing the application of the specified StarterKit, including its TLS certificate,
+// and removes the HTTPRoute it replaces.
+func (r *StarterKitReconciler) reconcileRoute(ctx context.Context, instance *devxv1alpha1.StarterKit, result *ctrl.Result, reqLogger logr.Logger) error {
+	var err error
 
-		createdHook, _, err := client.Repositories.CreateHook(ctx, instance.Spec.TemplateRepo.Owner, instance.Spec.TemplateRepo.Name, &hook)
+	// Create Certificate, or remove it once it is no longer requested
+	var certificate *corev1.Secret
+	if wantsCertificate(instance) {
+		certificate, err = r.reconcileCertificate(ctx, instance, reqLogger)
 		if err != nil {
-			return reconcile.Result{}, err
+			return err
+		}
+		if certificate == nil {
+			// Check back until cert-manager has issued the certificate
+			requeueAfter(result, certificateRequeueDelay)
+		}
+	} else {
+		if err := r.deleteOwned(ctx, instance, emptyCertificate(), reqLogger); err != nil {
+			return err
+		}
+		if ref := instance.Spec.Route.CertificateSecretRef; ref != nil && instance.Spec.Route.Termination != "" {
+			certificate = &corev1.Secret{}
+			err = r.Client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: instance.Namespace}, certificate)
+			if err != nil {
+				reqLogger.Error(err, "Error fetching Route certificate Secret", "Secret.Name", ref.Name)
+				return err
+			}
 		}
-		reqLogger.Info("Webhook created successfully", "Hook URL", *createdHook.URL)
+	}
+
+	// Create Route
+	reqLogger.Info("Configuring Route")
+	route := newRouteForCR(instance, certificate)
+
+	// Set StarterKit instance as the owner and controller
+	if err := controllerutil.SetControllerReference(instance, route, r.Scheme); err != nil {
+		reqLogger.Error(err, "Error setting Route on StarterKit")
+		return err
+	}
+
+	// Check if this Route already exists
+	foundRoute := &routev1.Route{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, foundRoute)
+	if err != nil && errors.IsNotFound(err) {
+		reqLogger.Info("Creating a new Route", "Route.Namespace", route.Namespace, "Route.Name", route.Name)
+		err = r.Client.Create(ctx, route)
+		if err != nil {
+			reqLogger.Error(err, "Error creating Route")
+			return err
+		}
+
+		// Route created successfully
+		reqLogger.Info("Route created successfully")
 	} else if err != nil {
-		reqLogger.Error(err, "Error fetching Build")
-		return reconcile.Result{}, err
+		reqLogger.Error(err, "Error fetching Route")
+		return err
+	} else if mergeRoute(foundRoute, route) {
+		reqLogger.Info("Updating Route", "Route.Namespace", foundRoute.Namespace, "Route.Name", foundRoute.Name)
+		err = r.Client.Update(ctx, foundRoute)
+		if err != nil {
+			reqLogger.Error(err, "Error updating Route")
+			return err
+		}
 	} else {
-		// Build already exists - don't requeue
-		reqLogger.Info("Skip reconcile: Build already exists", "Build.Namespace", foundBuild.Namespace, "Build.Name", foundBuild.Name)
+		// Route already exists - don't requeue
+		reqLogger.Info("Skip reconcile: Route already exists", "Route.Namespace", foundRoute.Namespace, "Route.Name", foundRoute.Name)
+	}
+
+	// Remove the HTTPRoute in case the StarterKit was switched back from the Gateway API
+	return r.deleteOwned(ctx, instance, emptyHTTPRoute(), reqLogger)
+}
+
+// Creates the Service for the application of the specified StarterKit, or updates its ports when the ports of the
+// StarterKit changed.
+func (r *StarterKitReconciler) reconcileService(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	// Create Service
+	reqLogger.Info("Configuring Service")
+	service := newServiceForCR(instance)
+
+	// Set StarterKit instance as the owner and controller
+	if err := controllerutil.SetControllerReference(instance, service, r.Scheme); err != nil {
+		reqLogger.Error(err, "Error setting Service on StarterKit")
+		return err
+	}
+
+	// Check if this Service already exists
+	foundService := &corev1.Service{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: service.Name, Namespace: service.Namespace}, foundService)
+	if err != nil && errors.IsNotFound(err) {
+		reqLogger.Info("Creating a new Service", "Service.Namespace", service.Namespace, "Service.Name", service.Name)
+		err = r.Client.Create(ctx, service)
+		if err != nil {
+			reqLogger.Error(err, "Error creating Service")
+			return err
+		}
+
+		// Service created successfully
+		reqLogger.Info("Service created successfully")
+	} else if err != nil {
+		reqLogger.Error(err, "Error fetching Service")
+		return err
+	} else if !equality.Semantic.DeepEqual(foundService.Spec.Ports, service.Spec.Ports) {
+		reqLogger.Info("Updating Service", "Service.Namespace", foundService.Namespace, "Service.Name", foundService.Name)
+		foundService.Spec.Ports = service.Spec.Ports
+		if err := r.Client.Update(ctx, foundService); err != nil {
+			reqLogger.Error(err, "Error updating Service")
+			return err
+		}
+	} else {
+		// Service already exists - don't requeue
+		reqLogger.Info("Skip reconcile: Service already exists", "Service.Namespace", foundService.Namespace, "Service.Name", foundService.Name)
 	}
 
+	return nil
+}
+
+// Creates the DeploymentConfig for the application of the specified StarterKit, or updates it when its image, ports
+// or bindings changed.
+func (r *StarterKitReconciler) reconcileDeployment(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
 	// Create Deployment
 	reqLogger.Info("Configuring Deployment")
 	deployment := newDeploymentForCR(instance)
@@ -325,77 +556,111 @@ func (r *StarterKitReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// Set StarterKit instance as the owner and controller
 	if err := controllerutil.SetControllerReference(instance, deployment, r.Scheme); err != nil {
 		reqLogger.Error(err, "Error setting Deployment on StarterKit")
-		return reconcile.Result{}, err
+		return err
+	}
+
+	// Resolve the image the Deployment is pinned to
+	pinnedImage := ""
+	if instance.Spec.PinnedImage != "" {
+		image := &imagev1.ImageStream{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, image); err != nil {
+			reqLogger.Error(err, "Error fetching ImageStream")
+			return err
+		}
+		if image.Status.DockerImageRepository == "" {
+			reqLogger.Info("Waiting for the ImageStream to be assigned a repository")
+			return nil
+		}
+		pinnedImage = image.Status.DockerImageRepository + "@" + instance.Spec.PinnedImage
 	}
 
 	// Check if this Deployment already exists
 	foundDeployment := &appsv1.DeploymentConfig{}
-	err = r.Client.Get(ctx, types.NamespacedName{Name: deployment.Name, Namespace: deployment.Namespace}, foundDeployment)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: deployment.Name, Namespace: deployment.Namespace}, foundDeployment)
 	if err != nil && errors.IsNotFound(err) {
+		pinImage(deployment, instance.Name, pinnedImage)
+		injectBindings(&deployment.Spec.Template.Spec, instance.Name, instance)
 		reqLogger.Info("Creating a new Deployment", "Deployment.Namespace", deployment.Namespace, "Deployment.Name", deployment.Name)
 		err = r.Client.Create(ctx, deployment)
 		if err != nil {
 			reqLogger.Error(err, "Error creating new DeploymentConfig")
-			return reconcile.Result{}, err
+			return err
 		}
 
 		// Deployment created successfully
 		reqLogger.Info("Deployment created successfully")
 	} else if err != nil {
 		reqLogger.Error(err, "Error fetching DeploymentConfig")
-		return reconcile.Result{}, err
+		return err
+	} else if mergeDeployment(foundDeployment, deployment, instance, pinnedImage) {
+		reqLogger.Info("Updating Deployment", "Deployment.Namespace", foundDeployment.Namespace, "Deployment.Name", foundDeployment.Name, "pinnedImage", pinnedImage)
+		if err := r.Client.Update(ctx, foundDeployment); err != nil {
+			reqLogger.Error(err, "Error updating DeploymentConfig")
+			return err
+		}
+		reqLogger.Info("Deployment updated successfully")
 	} else {
 		// Deployment already exists - don't requeue
 		reqLogger.Info("Skip reconcile: Deployment already exists", "Deployment.Namespace", foundDeployment.Namespace, "Deployment.Name", foundDeployment.Name)
 	}
 
-	// ========================================================================
-	// *** handle cleanup of other resources ***
-
-	// Check if the StarterKit instance is marked to be deleted, which is
-	// indicated by the deletion timestamp being set.
-	isMarkedToBeDeleted := instance.GetDeletionTimestamp() != nil
-	if isMarkedToBeDeleted {
-		if contains(instance.GetFinalizers(), starterkitFinalizer) {
-			// Run finalization logic for starterkitFinalizer. If the
-			// finalization logic fails, don't remove the finalizer so
-			// that we can retry during the next reconciliation.
-			if err := r.finalizeStarterKit(reqLogger, req, instance, client); err != nil {
-				return reconcile.Result{}, err
-			}
+	return nil
+}
 
-			// Remove starterkitFinalizer. Once all finalizers have been
-			// removed, the object will be deleted.
-			controllerutil.RemoveFinalizer(instance, starterkitFinalizer)
-			err := r.Client.Update(ctx, instance)
-			if err != nil {
-				return reconcile.Result{}, err
+// Copies the fields of the desired DeploymentConfig that follow the spec of the specified StarterKit, i.e. the pinned
+// image, the container ports and the injected bindings, onto the found one. Returns true if the found
+// DeploymentConfig was changed and needs to be updated.
+func mergeDeployment(found *appsv1.DeploymentConfig, desired *appsv1.DeploymentConfig, cr *devxv1alpha1.StarterKit, pinnedImage string) bool {
+	changed := pinImage(found, cr.Name, pinnedImage)
+	if found.Spec.Template != nil && desired.Spec.Template != nil {
+		for _, d := range desired.Spec.Template.Spec.Containers {
+			for i := range found.Spec.Template.Spec.Containers {
+				if c := &found.Spec.Template.Spec.Containers[i]; c.Name == d.Name && !equality.Semantic.DeepEqual(c.Ports, d.Ports) {
+					c.Ports = d.Ports
+					changed = true
+				}
 			}
 		}
-		return reconcile.Result{}, nil
 	}
+	if injectBindings(&found.Spec.Template.Spec, cr.Name, cr) {
+		changed = true
+	}
+	return changed
+}
 
-	// Add finalizer for this CR
-	if !contains(instance.GetFinalizers(), starterkitFinalizer) {
-		reqLogger.Info("Adding finalizer to StarterKit")
-		if err := r.addFinalizer(reqLogger, instance); err != nil {
-			return reconcile.Result{}, err
+// Pins the specified container of the DeploymentConfig to the given image and disables its automatic image change
+// triggers, or re-enables the triggers when no image is given, so that the latest image is rolled out again. Returns
+// true if the DeploymentConfig was changed and needs to be updated.
+func pinImage(deployment *appsv1.DeploymentConfig, container string, image string) bool {
+	changed := false
+	automatic := image == ""
+	for _, t := range deployment.Spec.Triggers {
+		if t.Type == appsv1.DeploymentTriggerOnImageChange && t.ImageChangeParams != nil && t.ImageChangeParams.Automatic != automatic {
+			t.ImageChangeParams.Automatic = automatic
+			changed = true
 		}
-	} else {
-		reqLogger.Info("StarterKit already has finalizer")
 	}
-
-	return ctrl.Result{}, nil
+	if image == "" || deployment.Spec.Template == nil {
+		return changed
+	}
+	for i := range deployment.Spec.Template.Spec.Containers {
+		if c := &deployment.Spec.Template.Spec.Containers[i]; c.Name == container && c.Image != image {
+			c.Image = image
+			changed = true
+		}
+	}
+	return changed
 }
 
 // Adds the 'finalizeStarterKit' finalizer to the specified StarterKit. The finalizer is responsible for additional cleanup when
 // deleting a StarterKit.
 func (r *StarterKitReconciler) addFinalizer(reqLogger logr.Logger, s *devxv1alpha1.StarterKit) error {
 	reqLogger.Info("Adding Finalizer for the StarterKit")
+	patch := client.MergeFrom(s.DeepCopy())
 	controllerutil.AddFinalizer(s, starterkitFinalizer)
 
-	// Update CR
-	err := r.Client.Update(context.TODO(), s)
+	// Patch CR, so that template defaults merged into the spec are not persisted
+	err := r.Client.Patch(context.TODO(), s, patch)
 	if err != nil {
 		reqLogger.Error(err, "Failed to update StarterKit with finalizer")
 		return err
@@ -403,29 +668,94 @@ func (r *StarterKitReconciler) addFinalizer(reqLogger logr.Logger, s *devxv1alph
 	return nil
 }
 
+// Removes the 'finalizeStarterKit' finalizer from the specified StarterKit once the additional cleanup is done.
+func (r *StarterKitReconciler) removeFinalizer(reqLogger logr.Logger, s *devxv1alpha1.StarterKit) error {
+	patch := client.MergeFrom(s.DeepCopy())
+	controllerutil.RemoveFinalizer(s, starterkitFinalizer)
+
+	// Patch CR, so that template defaults merged into the spec are not persisted
+	err := r.Client.Patch(context.TODO(), s, patch)
+	if err != nil {
+		reqLogger.Error(err, "Failed to remove finalizer from StarterKit")
+		return err
+	}
+	return nil
+}
+
 // Finalizer that runs during Reconcile() if the StarterKit has been marked for deletion.
 // This function performs additional cleanup, namely deleting the created GitHub repo if the DEVX_DEV_MODE environment variable is set to 'true'.
-func (r *StarterKitReconciler) finalizeStarterKit(reqLogger logr.Logger, request reconcile.Request, s *devxv1alpha1.StarterKit, githubClient *github.Client) error {
+func (r *StarterKitReconciler) finalizeStarterKit(reqLogger logr.Logger, request reconcile.Request, s *devxv1alpha1.StarterKit) error {
 	// if we're running in development mode, cleanup the github repo if present
 	ctx := context.Background()
 	if devxDevMode, ok := os.LookupEnv("DEVX_DEV_MODE"); ok {
-		if devxDevMode == "true" {
+		if devxDevMode == "true" && s.Status.TargetRepo != "" {
 			reqLogger.Info("Running in development mode")
-			reqLogger.Info("Deleting target GitHub repo", "TargetRepo", s.Status.TargetRepo)
-			// note that this requires the GitHub access token to have admin or delete_repo rights
-			_, err := githubClient.Repositories.Delete(ctx, s.Spec.TemplateRepo.Owner, s.Spec.TemplateRepo.Name)
-			if err != nil {
+			githubTokenValue, err := r.fetchGitHubSecret(s, &request, reqLogger)
+			if err != nil && errors.IsNotFound(err) {
+				reqLogger.Info("Skip deleting target GitHub repo: GitHub secret not found", "TargetRepo", s.Status.TargetRepo)
+			} else if err != nil {
 				return err
+			} else {
+				reqLogger.Info("Deleting target GitHub repo", "TargetRepo", s.Status.TargetRepo)
+				// note that this requires the GitHub access token to have admin or delete_repo rights
+				resp, err := r.getGitHubClient(githubTokenValue, reqLogger).Repositories.Delete(ctx, s.Spec.TemplateRepo.Owner, s.Spec.TemplateRepo.Name)
+				if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+					return err
+				}
 			}
 		}
 	}
+	// environments in other namespaces are not owned by the StarterKit, so they are not garbage collected
+	if err := r.deleteEnvironments(ctx, s, map[string]bool{}, reqLogger); err != nil {
+		return err
+	}
 	reqLogger.Info("Successfully finalized StarterKit")
 	return nil
 }
 
+// Records in the status of the specified StarterKit whether its template exists. StarterKits without a template
+// get no condition.
+func (r *StarterKitReconciler) setTemplateFound(ctx context.Context, instance *devxv1alpha1.StarterKit, found bool) error {
+	if instance.Spec.TemplateRef == "" {
+		return nil
+	}
+	status := instance.Status.DeepCopy()
+	condition := metav1.Condition{
+		Type:               devxv1alpha1.ConditionTemplateFound,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Found",
+		Message:            "StarterKitTemplate " + instance.Spec.TemplateRef + " found",
+		ObservedGeneration: instance.Generation,
+	}
+	if !found {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "NotFound"
+		condition.Message = "Waiting for StarterKitTemplate " + instance.Spec.TemplateRef + " to be created"
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.updateStarterKitStatus(ctx, instance)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *StarterKitReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&devxv1alpha1.StarterKit{}).
-		Complete(r)
+		Owns(&imagev1.ImageStream{}).
+		Owns(&routev1.Route{}).
+		Owns(&appsv1.DeploymentConfig{}).
+		Watches(&source.Kind{Type: &buildv1.Build{}}, handler.EnqueueRequestsFromMapFunc(r.starterKitForBuild)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.starterKitsForSecret)).
+		Watches(&source.Kind{Type: &devxv1alpha1.StarterKitTemplate{}}, handler.EnqueueRequestsFromMapFunc(r.starterKitsForTemplate))
+
+	// The Gateway API is optional, so HTTPRoutes are only watched if their CRD is installed
+	if _, err := mgr.GetRESTMapper().RESTMapping(httpRouteGVK.GroupKind(), httpRouteGVK.Version); err == nil {
+		b = b.Owns(emptyHTTPRoute())
+	} else if !meta.IsNoMatchError(err) {
+		return err
+	}
+	return b.Complete(r)
 }
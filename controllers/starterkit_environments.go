@@ -0,0 +1,438 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	appsv1 "github.com/openshift/api/apps/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Labels identifying the StarterKit and environment of resources deployed to other namespaces, which cannot be
+// owned by the StarterKit
+const (
+	starterKitNameLabel      = "devx.ibm.com/starterkit-name"
+	starterKitNamespaceLabel = "devx.ibm.com/starterkit-namespace"
+	environmentLabel         = "devx.ibm.com/environment"
+)
+
+// environmentRequeueDelay is how long to wait before checking whether the Route of an environment was admitted
+const environmentRequeueDelay = 15 * time.Second
+
+// Returns a copy of the specified StarterKit describing its deployment in an environment, so that the resources of
+// the environment can be derived with the same builders as the ones of the StarterKit.
+func environmentCR(cr *devxv1alpha1.StarterKit, env *devxv1alpha1.StarterKitSpecEnvironment) *devxv1alpha1.StarterKit {
+	e := cr.DeepCopy()
+	e.Namespace = env.Namespace
+	e.Spec.Route = devxv1alpha1.StarterKitSpecRoute{
+		Host:        env.Host,
+		Termination: string(routev1.TLSTerminationEdge),
+	}
+	e.Spec.Options.Env = nil
+	for _, v := range cr.Spec.Options.Env {
+		e.Spec.Options.Env = setEnvVar(e.Spec.Options.Env, v)
+	}
+	for _, v := range env.Env {
+		e.Spec.Options.Env = setEnvVar(e.Spec.Options.Env, v)
+	}
+	return e
+}
+
+// Sets the specified environment variable, replacing a variable of the same name.
+func setEnvVar(env []corev1.EnvVar, v corev1.EnvVar) []corev1.EnvVar {
+	for i := range env {
+		if env[i].Name == v.Name {
+			env[i] = v
+			return env
+		}
+	}
+	return append(env, v)
+}
+
+// Labels a resource of an environment so that it is attributed to the StarterKit and the environment.
+func labelEnvironment(obj metav1.Object, cr *devxv1alpha1.StarterKit, env *devxv1alpha1.StarterKitSpecEnvironment) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[starterKitNameLabel] = cr.Name
+	labels[starterKitNamespaceLabel] = cr.Namespace
+	labels[environmentLabel] = env.Name
+	obj.SetLabels(labels)
+}
+
+// Create a new DeploymentConfig rolling out the image promoted to an environment
+func newEnvironmentDeploymentForCR(cr *devxv1alpha1.StarterKit, env *devxv1alpha1.StarterKitSpecEnvironment) *appsv1.DeploymentConfig {
+	deployment := newDeploymentForCR(environmentCR(cr, env))
+	labelEnvironment(deployment, cr, env)
+	from := &deployment.Spec.Triggers[0].ImageChangeParams.From
+	from.Name = cr.Name + ":" + env.Name
+	from.Namespace = cr.Namespace
+	return deployment
+}
+
+// Create a new Service for an environment
+func newEnvironmentServiceForCR(cr *devxv1alpha1.StarterKit, env *devxv1alpha1.StarterKitSpecEnvironment) *corev1.Service {
+	service := newServiceForCR(environmentCR(cr, env))
+	labelEnvironment(service, cr, env)
+	return service
+}
+
+// Create a new Route exposing an environment
+func newEnvironmentRouteForCR(cr *devxv1alpha1.StarterKit, env *devxv1alpha1.StarterKitSpecEnvironment) *routev1.Route {
+	route := newRouteForCR(environmentCR(cr, env), nil)
+	labelEnvironment(route, cr, env)
+	return route
+}
+
+// Create a new RoleBinding allowing an environment to pull the images of the StarterKit
+func newImagePullerRoleBindingForCR(cr *devxv1alpha1.StarterKit, env *devxv1alpha1.StarterKitSpecEnvironment) *rbacv1.RoleBinding {
+	binding := &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "RoleBinding",
+			APIVersion: "rbac.authorization.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-image-puller-%s", cr.Name, env.Name),
+			Namespace: cr.Namespace,
+			Labels: map[string]string{
+				"app":  cr.Name,
+				"devx": "",
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     "system:image-puller",
+		},
+		// Only the service account the DeploymentConfig of the environment runs as may pull the images
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      "default",
+				Namespace: env.Namespace,
+			},
+		},
+	}
+	labelEnvironment(binding, cr, env)
+	return binding
+}
+
+// Promotes the images of the specified StarterKit through its environments and deploys the application in each of
+// them. The first environment receives the latest built image and every following environment the image of the
+// previous one, either automatically or, for manual environments, once the promotion was approved with the promote
+// annotation of the environment. Images are promoted by tagging them with the name of the environment in the
+// ImageStream of the StarterKit, which the DeploymentConfig of the environment follows. Resources of environments
+// that were removed from the spec are deleted.
+func (r *StarterKitReconciler) reconcileEnvironments(ctx context.Context, instance *devxv1alpha1.StarterKit, result *ctrl.Result, reqLogger logr.Logger) error {
+	if len(instance.Spec.Environments) == 0 && len(instance.Status.Environments) == 0 && meta.FindStatusCondition(instance.Status.Conditions, devxv1alpha1.ConditionEnvironmentsAllowed) == nil {
+		return nil
+	}
+
+	image := &imagev1.ImageStream{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, image); err != nil {
+		reqLogger.Error(err, "Error fetching ImageStream")
+		return err
+	}
+	imageChanged := false
+
+	previous := map[string]devxv1alpha1.StarterKitStatusEnvironment{}
+	for _, st := range instance.Status.Environments {
+		previous[st.Name] = st
+	}
+	keep := map[string]bool{}
+	namespaces := map[string]bool{}
+	var refused []string
+	var statuses []devxv1alpha1.StarterKitStatusEnvironment
+	source := instance.Status.ImageDigest
+	for i := range instance.Spec.Environments {
+		env := &instance.Spec.Environments[i]
+		st, ok := previous[env.Name]
+		if !ok {
+			st = devxv1alpha1.StarterKitStatusEnvironment{Name: env.Name}
+		}
+		delete(previous, env.Name)
+
+		// Nothing is promoted to or deployed in a namespace that did not opt in, and resources deployed before the
+		// namespace opted out are deleted
+		reason, err := r.checkEnvironmentNamespace(ctx, instance, env, namespaces)
+		if err != nil {
+			reqLogger.Error(err, "Error checking environment namespace", "environment", env.Name)
+			return err
+		}
+		if reason != "" {
+			refused = append(refused, fmt.Sprintf("%s (%s)", env.Name, reason))
+			st.PendingDigest = ""
+			st.URL = ""
+			statuses = append(statuses, st)
+			source = st.Digest
+			continue
+		}
+		keep[env.Namespace+"/"+env.Name] = true
+
+		// Promotion gate
+		promote := false
+		st.PendingDigest = ""
+		if source != "" && source != st.Digest {
+			if env.Promotion == devxv1alpha1.PromotionManual {
+				st.PendingDigest = source
+				request := instance.GetAnnotations()[devxv1alpha1.PromoteAnnotationPrefix+env.Name]
+				promote = request != "" && request != st.PromotionRequest
+			} else {
+				promote = true
+			}
+		}
+		// Approvals given while no image was pending are consumed, so they cannot approve a later image
+		if request := instance.GetAnnotations()[devxv1alpha1.PromoteAnnotationPrefix+env.Name]; request != "" {
+			st.PromotionRequest = request
+		}
+		if promote {
+			reqLogger.Info("Promoting image", "environment", env.Name, "digest", source)
+			if setImageStreamTag(image, env.Name, source) {
+				imageChanged = true
+			}
+			now := metav1.Now()
+			st.Digest = source
+			st.PendingDigest = ""
+			st.PromotionTimestamp = &now
+		}
+
+		route, err := r.reconcileEnvironment(ctx, instance, env, reqLogger)
+		if err != nil {
+			return err
+		}
+		st.URL = urlForRoute(route)
+		if route.Spec.Host == "" {
+			requeueAfter(result, environmentRequeueDelay)
+		}
+		statuses = append(statuses, st)
+		source = st.Digest
+	}
+
+	// Tags of removed environments
+	for name := range previous {
+		for i, tag := range image.Spec.Tags {
+			if tag.Name == name {
+				image.Spec.Tags = append(image.Spec.Tags[:i], image.Spec.Tags[i+1:]...)
+				imageChanged = true
+				break
+			}
+		}
+	}
+	if imageChanged {
+		if err := r.Client.Update(ctx, image); err != nil {
+			reqLogger.Error(err, "Error updating ImageStream")
+			return err
+		}
+		reqLogger.Info("ImageStream updated successfully")
+	}
+
+	if err := r.deleteEnvironments(ctx, instance, keep, reqLogger); err != nil {
+		return err
+	}
+
+	status := instance.Status.DeepCopy()
+	status.Environments = statuses
+	if len(refused) > 0 {
+		reqLogger.Info("Refusing to deploy environments", "environments", refused)
+		// Namespaces are not watched, so check back until they opted in
+		requeueAfter(result, environmentRequeueDelay)
+	}
+	return r.updateReadyCondition(ctx, instance, status, readyCondition{
+		Type:           devxv1alpha1.ConditionEnvironmentsAllowed,
+		Reason:         "Allowed",
+		Message:        "All environment namespaces are allowed",
+		WaitingReason:  "NamespaceNotAllowed",
+		WaitingMessage: "Refusing to deploy environments",
+	}, len(instance.Spec.Environments) == 0, refused)
+}
+
+// Returns why the application of the specified StarterKit may not be deployed to the namespace of the given
+// environment, or an empty string if it may. The namespace must not be the one of the StarterKit or of another
+// environment, which are tracked in the given set, and must opt in by naming the namespace of the StarterKit in its
+// EnvironmentSourceLabel.
+func (r *StarterKitReconciler) checkEnvironmentNamespace(ctx context.Context, instance *devxv1alpha1.StarterKit, env *devxv1alpha1.StarterKitSpecEnvironment, namespaces map[string]bool) (string, error) {
+	if env.Namespace == instance.Namespace {
+		return "namespace of the StarterKit", nil
+	}
+	if namespaces[env.Namespace] {
+		return "namespace of another environment", nil
+	}
+	namespaces[env.Namespace] = true
+
+	namespace := &corev1.Namespace{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: env.Namespace}, namespace)
+	if err != nil && errors.IsNotFound(err) {
+		return fmt.Sprintf("namespace %s not found", env.Namespace), nil
+	} else if err != nil {
+		return "", err
+	}
+	if namespace.Labels[devxv1alpha1.EnvironmentSourceLabel] != instance.Namespace {
+		return fmt.Sprintf("namespace %s is not labeled %s=%s", env.Namespace, devxv1alpha1.EnvironmentSourceLabel, instance.Namespace), nil
+	}
+	return "", nil
+}
+
+// Creates the resources of the specified environment, or updates them when the spec of the StarterKit or of the
+// environment changed. Returns the Route of the environment.
+func (r *StarterKitReconciler) reconcileEnvironment(ctx context.Context, instance *devxv1alpha1.StarterKit, env *devxv1alpha1.StarterKitSpecEnvironment, reqLogger logr.Logger) (*routev1.Route, error) {
+	binding := newImagePullerRoleBindingForCR(instance, env)
+	if err := controllerutil.SetControllerReference(instance, binding, r.Scheme); err != nil {
+		reqLogger.Error(err, "Error setting RoleBinding on StarterKit")
+		return nil, err
+	}
+	route := newEnvironmentRouteForCR(instance, env)
+	objects := []client.Object{
+		binding,
+		newEnvironmentDeploymentForCR(instance, env),
+		newEnvironmentServiceForCR(instance, env),
+		route,
+	}
+	for _, obj := range objects {
+		found := obj.DeepCopyObject().(client.Object)
+		err := r.Client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
+		if err != nil && errors.IsNotFound(err) {
+			reqLogger.Info("Creating a new environment resource", "environment", env.Name, "Kind", fmt.Sprintf("%T", obj), "Namespace", obj.GetNamespace(), "Name", obj.GetName())
+			if err := r.Client.Create(ctx, obj); err != nil {
+				reqLogger.Error(err, "Error creating environment resource")
+				return nil, err
+			}
+			reqLogger.Info("Environment resource created successfully")
+		} else if err != nil {
+			reqLogger.Error(err, "Error fetching environment resource")
+			return nil, err
+		} else {
+			if mergeEnvironmentResource(found, obj) {
+				reqLogger.Info("Updating environment resource", "environment", env.Name, "Kind", fmt.Sprintf("%T", obj), "Namespace", found.GetNamespace(), "Name", found.GetName())
+				if err := r.Client.Update(ctx, found); err != nil {
+					reqLogger.Error(err, "Error updating environment resource")
+					return nil, err
+				}
+			}
+			if foundRoute, ok := found.(*routev1.Route); ok {
+				route = foundRoute
+			}
+		}
+	}
+	return route, nil
+}
+
+// Copies the fields of the desired environment resource that follow the spec of the StarterKit and the environment,
+// i.e. the environment variables and ports of the application, the exposure of the Route and the subjects of the
+// RoleBinding, onto the found one. Returns true if the found resource was changed and needs to be updated.
+func mergeEnvironmentResource(found client.Object, desired client.Object) bool {
+	changed := false
+	switch found := found.(type) {
+	case *appsv1.DeploymentConfig:
+		desired := desired.(*appsv1.DeploymentConfig)
+		if found.Spec.Template == nil {
+			found.Spec.Template = desired.Spec.Template
+			return true
+		}
+		for _, d := range desired.Spec.Template.Spec.Containers {
+			for i := range found.Spec.Template.Spec.Containers {
+				c := &found.Spec.Template.Spec.Containers[i]
+				if c.Name != d.Name {
+					continue
+				}
+				if !equality.Semantic.DeepEqual(c.Env, d.Env) {
+					c.Env = d.Env
+					changed = true
+				}
+				if !equality.Semantic.DeepEqual(c.Ports, d.Ports) {
+					c.Ports = d.Ports
+					changed = true
+				}
+			}
+		}
+	case *corev1.Service:
+		desired := desired.(*corev1.Service)
+		if !equality.Semantic.DeepEqual(found.Spec.Ports, desired.Spec.Ports) {
+			found.Spec.Ports = desired.Spec.Ports
+			changed = true
+		}
+	case *routev1.Route:
+		changed = mergeRoute(found, desired.(*routev1.Route))
+	case *rbacv1.RoleBinding:
+		desired := desired.(*rbacv1.RoleBinding)
+		if !equality.Semantic.DeepEqual(found.Subjects, desired.Subjects) {
+			found.Subjects = desired.Subjects
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Deletes the resources of the environments of the specified StarterKit that are not kept. The keys of kept
+// environments are formed by their namespace and name.
+func (r *StarterKitReconciler) deleteEnvironments(ctx context.Context, instance *devxv1alpha1.StarterKit, keep map[string]bool, reqLogger logr.Logger) error {
+	lists := []client.ObjectList{
+		&rbacv1.RoleBindingList{},
+		&appsv1.DeploymentConfigList{},
+		&corev1.ServiceList{},
+		&routev1.RouteList{},
+	}
+	for _, list := range lists {
+		if err := r.Client.List(ctx, list, client.MatchingLabels{starterKitNameLabel: instance.Name, starterKitNamespaceLabel: instance.Namespace}); err != nil {
+			reqLogger.Error(err, "Error listing environment resources")
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			env := obj.GetLabels()[environmentLabel]
+			// RoleBindings live in the namespace of the StarterKit and name a service account of the environment
+			// namespace as subject, or the group of all its service accounts when created by earlier versions
+			namespace := obj.GetNamespace()
+			if binding, ok := obj.(*rbacv1.RoleBinding); ok && len(binding.Subjects) > 0 {
+				namespace = binding.Subjects[0].Namespace
+				if binding.Subjects[0].Kind == rbacv1.GroupKind {
+					namespace = strings.TrimPrefix(binding.Subjects[0].Name, "system:serviceaccounts:")
+				}
+			}
+			if keep[namespace+"/"+env] {
+				continue
+			}
+			reqLogger.Info("Deleting environment resource", "environment", env, "Kind", fmt.Sprintf("%T", obj), "Namespace", obj.GetNamespace(), "Name", obj.GetName())
+			if err := r.Client.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+				reqLogger.Error(err, "Error deleting environment resource")
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Gateway API kinds
+var (
+	httpRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+	gatewayGVK   = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "Gateway"}
+)
+
+// Returns an empty HTTPRoute to fetch objects into.
+func emptyHTTPRoute() *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteGVK)
+	return route
+}
+
+// Create a new HTTPRoute. Fields defaulted by the Gateway API are set explicitly, so that the HTTPRoute can be
+// compared with the one found in the cluster. Returns an error if the route port does not match any port of the
+// application.
+func newHTTPRouteForCR(cr *devxv1alpha1.StarterKit) (*unstructured.Unstructured, error) {
+	gatewayAPI := cr.Spec.Exposure.GatewayAPI
+
+	parentRef := map[string]interface{}{
+		"group": gatewayGVK.Group,
+		"kind":  gatewayGVK.Kind,
+		"name":  gatewayAPI.GatewayRef.Name,
+	}
+	if gatewayAPI.GatewayRef.Namespace != "" {
+		parentRef["namespace"] = gatewayAPI.GatewayRef.Namespace
+	}
+	if gatewayAPI.GatewayRef.SectionName != "" {
+		parentRef["sectionName"] = gatewayAPI.GatewayRef.SectionName
+	}
+
+	port, err := routeTargetPort(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := gatewayAPI.Paths
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+	var matches []interface{}
+	for _, path := range paths {
+		matches = append(matches, map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":  "PathPrefix",
+				"value": path,
+			},
+		})
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": matches,
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"group":  "",
+						"kind":   "Service",
+						"name":   cr.Name,
+						"port":   int64(port.Port),
+						"weight": int64(1),
+					},
+				},
+			},
+		},
+	}
+	if len(gatewayAPI.Hostnames) > 0 {
+		var hostnames []interface{}
+		for _, hostname := range gatewayAPI.Hostnames {
+			hostnames = append(hostnames, hostname)
+		}
+		spec["hostnames"] = hostnames
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteGVK)
+	route.SetName(cr.Name)
+	route.SetNamespace(cr.Namespace)
+	route.SetLabels(map[string]string{
+		"app":  cr.Name,
+		"devx": "",
+	})
+	route.Object["spec"] = spec
+	return route, nil
+}
+
+// Creates or updates the HTTPRoute exposing the application of the specified StarterKit, and removes the OpenShift
+// Route and Certificate it replaces.
+func (r *StarterKitReconciler) reconcileHTTPRoute(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	reqLogger.Info("Configuring HTTPRoute")
+	route, err := newHTTPRouteForCR(instance)
+	if err != nil {
+		reqLogger.Error(err, "Error resolving route port")
+		return err
+	}
+
+	// Set StarterKit instance as the owner and controller
+	if err := controllerutil.SetControllerReference(instance, route, r.Scheme); err != nil {
+		reqLogger.Error(err, "Error setting HTTPRoute on StarterKit")
+		return err
+	}
+
+	// Check if this HTTPRoute already exists
+	foundRoute := &unstructured.Unstructured{}
+	foundRoute.SetGroupVersionKind(httpRouteGVK)
+	err = r.Client.Get(ctx, types.NamespacedName{Name: route.GetName(), Namespace: route.GetNamespace()}, foundRoute)
+	if err != nil && errors.IsNotFound(err) {
+		reqLogger.Info("Creating a new HTTPRoute", "HTTPRoute.Namespace", route.GetNamespace(), "HTTPRoute.Name", route.GetName())
+		err = r.Client.Create(ctx, route)
+		if err != nil {
+			reqLogger.Error(err, "Error creating HTTPRoute")
+			return err
+		}
+
+		// HTTPRoute created successfully
+		reqLogger.Info("HTTPRoute created successfully")
+	} else if err != nil {
+		reqLogger.Error(err, "Error fetching HTTPRoute")
+		return err
+	} else if !equality.Semantic.DeepEqual(foundRoute.Object["spec"], route.Object["spec"]) {
+		reqLogger.Info("Updating HTTPRoute", "HTTPRoute.Namespace", foundRoute.GetNamespace(), "HTTPRoute.Name", foundRoute.GetName())
+		foundRoute.Object["spec"] = route.Object["spec"]
+		err = r.Client.Update(ctx, foundRoute)
+		if err != nil {
+			reqLogger.Error(err, "Error updating HTTPRoute")
+			return err
+		}
+	}
+
+	// Remove the OpenShift Route and its Certificate in case the StarterKit was switched over to the Gateway API
+	for _, obj := range []client.Object{&routev1.Route{}, emptyCertificate()} {
+		if err := r.deleteOwned(ctx, instance, obj, reqLogger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the URL the HTTPRoute of the specified StarterKit serves the application on. The scheme is taken from the
+// Gateway listener the HTTPRoute attaches to, and the host from the HTTPRoute or listener hostname. An empty string
+// is returned when no hostname is known.
+func (r *StarterKitReconciler) httpRouteURL(ctx context.Context, instance *devxv1alpha1.StarterKit) (string, error) {
+	gatewayAPI := instance.Spec.Exposure.GatewayAPI
+	host := ""
+	if len(gatewayAPI.Hostnames) > 0 {
+		host = gatewayAPI.Hostnames[0]
+	}
+	scheme := "http://"
+
+	namespace := gatewayAPI.GatewayRef.Namespace
+	if namespace == "" {
+		namespace = instance.Namespace
+	}
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(gatewayGVK)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: gatewayAPI.GatewayRef.Name, Namespace: namespace}, gateway)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", err
+	}
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	for _, l := range listeners {
+		listener, _ := l.(map[string]interface{})
+		if gatewayAPI.GatewayRef.SectionName != "" && listener["name"] != gatewayAPI.GatewayRef.SectionName {
+			continue
+		}
+		if listener["protocol"] == "HTTPS" {
+			scheme = "https://"
+		}
+		// wildcard listener hostnames do not identify the application
+		if hostname, ok := listener["hostname"].(string); ok && host == "" && !strings.HasPrefix(hostname, "*") {
+			host = hostname
+		}
+		break
+	}
+
+	if host == "" {
+		return "", nil
+	}
+	path := "/"
+	if len(gatewayAPI.Paths) > 0 {
+		path = gatewayAPI.Paths[0]
+	}
+	return scheme + host + path, nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Returns the URL GitHub delivers webhooks to, which is served by the GitHubWebhookReceiver through its Route in
+// the operator namespace.
+func (r *StarterKitReconciler) githubWebhookURL(ctx context.Context) (string, error) {
+	route := &routev1.Route{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: WebhookReceiverName, Namespace: r.OperatorNamespace}, route); err != nil {
+		return "", err
+	}
+	if route.Spec.Host == "" {
+		return "", fmt.Errorf("route %s/%s has no host assigned", route.Namespace, route.Name)
+	}
+	return "https://" + route.Spec.Host + GitHubWebhookPath, nil
+}
+
+// Creates the webhook on the target GitHub repo of the specified StarterKit if it has not been previously created
+// and recorded on the StarterKit. Webhooks pointing at the BuildConfig webhook endpoint of the API server, which
+// were created by earlier versions of the operator, are replaced.
+func (r *StarterKitReconciler) reconcileGitHubHook(ctx context.Context, githubClient *github.Client, instance *devxv1alpha1.StarterKit, secretToken string, reqLogger logr.Logger) error {
+	if instance.Status.WebhookID != 0 {
+		return nil
+	}
+
+	reqLogger.Info("Configuring GitHub webhook")
+	hookURL, err := r.githubWebhookURL(ctx)
+	if err != nil {
+		reqLogger.Error(err, "Error fetching GitHub webhook receiver URL")
+		return err
+	}
+
+	owner := instance.Spec.TemplateRepo.Owner
+	repo := instance.Spec.TemplateRepo.Name
+	hooks, _, err := githubClient.Repositories.ListHooks(ctx, owner, repo, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		reqLogger.Error(err, "Error listing GitHub webhooks")
+		return err
+	}
+	legacyPath := fmt.Sprintf("/namespaces/%s/buildconfigs/%s/webhooks/", instance.Namespace, instance.Name)
+	for _, h := range hooks {
+		url, _ := h.Config["url"].(string)
+		if strings.Contains(strings.ToLower(url), strings.ToLower(legacyPath)) {
+			reqLogger.Info("Deleting legacy GitHub webhook", "Hook ID", h.GetID())
+			if _, err := githubClient.Repositories.DeleteHook(ctx, owner, repo, h.GetID()); err != nil {
+				reqLogger.Error(err, "Error deleting legacy GitHub webhook")
+				return err
+			}
+		}
+	}
+
+	hook := newGitHubHook(hookURL, secretToken, hookEventsForCR(instance))
+	createdHook, _, err := githubClient.Repositories.CreateHook(ctx, owner, repo, &hook)
+	if err != nil {
+		reqLogger.Error(err, "Error creating GitHub webhook")
+		return err
+	}
+	reqLogger.Info("Webhook created successfully", "Hook URL", createdHook.GetURL())
+
+	instance.Status.WebhookID = createdHook.GetID()
+	instance.Status.WebhookEvents = hook.Events
+	return r.updateStarterKitStatus(ctx, instance)
+}
+
+// Returns the configuration of the webhook GitHub delivers the specified events of a StarterKit target repo with.
+func newGitHubHook(hookURL string, secretToken string, events []string) github.Hook {
+	return github.Hook{
+		Config: map[string]interface{}{
+			"content_type": "json",
+			"url":          hookURL,
+			"secret":       secretToken,
+			"insecure_ssl": "0",
+		},
+		Events: events,
+		Active: github.Bool(true),
+	}
+}
+
+// Rotates the webhook secret of the specified StarterKit when the rotate-webhook-secret annotation changed or the
+// rotation interval elapsed. The new secret is set on the GitHub webhook first and only then stored in the Secret
+// shared with the BuildConfig trigger; the GitHub webhook is reverted if storing fails. The previous secret is kept
+// in the Secret so that deliveries signed before the rotation are still accepted. Returns the time until the next
+// periodic rotation is due, or zero if none is configured.
+func (r *StarterKitReconciler) reconcileWebhookSecretRotation(ctx context.Context, githubClient *github.Client, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) (time.Duration, error) {
+	if instance.Status.WebhookID == 0 {
+		return 0, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, secret); err != nil {
+		reqLogger.Error(err, "Error fetching Secret")
+		return 0, err
+	}
+
+	request := instance.GetAnnotations()[devxv1alpha1.RotateWebhookSecretAnnotation]
+	requested := request != "" && request != instance.Status.WebhookSecretRotationRequest
+	// Intervals that are not positive disable the periodic rotation
+	interval := instance.Spec.Webhook.SecretRotationInterval
+	periodic := interval != nil && interval.Duration > 0
+	if !requested && !periodic {
+		return 0, nil
+	}
+	if !requested {
+		last := secret.CreationTimestamp
+		if instance.Status.WebhookSecretRotationTimestamp != nil {
+			last = *instance.Status.WebhookSecretRotationTimestamp
+		}
+		if next := time.Until(last.Add(interval.Duration)); next > 0 {
+			return next, nil
+		}
+	}
+
+	reqLogger.Info("Rotating webhook secret")
+	token, err := GenerateRandomString(32)
+	if err != nil {
+		reqLogger.Error(err, "Error creating random string")
+		return 0, err
+	}
+	previousToken := string(secret.Data[webHookSecretKey])
+	hookURL, err := r.githubWebhookURL(ctx)
+	if err != nil {
+		reqLogger.Error(err, "Error fetching GitHub webhook receiver URL")
+		return 0, err
+	}
+
+	owner := instance.Spec.TemplateRepo.Owner
+	repo := instance.Spec.TemplateRepo.Name
+	hook := newGitHubHook(hookURL, token, hookEventsForCR(instance))
+	if _, _, err := githubClient.Repositories.EditHook(ctx, owner, repo, instance.Status.WebhookID, &hook); err != nil {
+		reqLogger.Error(err, "Error updating GitHub webhook secret")
+		return 0, err
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[webHookSecretKey] = []byte(token)
+	secret.Data[previousWebHookSecretKey] = []byte(previousToken)
+	if err := r.Client.Update(ctx, secret); err != nil {
+		reqLogger.Error(err, "Error updating Secret, reverting GitHub webhook secret")
+		hook := newGitHubHook(hookURL, previousToken, hookEventsForCR(instance))
+		if _, _, revertErr := githubClient.Repositories.EditHook(ctx, owner, repo, instance.Status.WebhookID, &hook); revertErr != nil {
+			reqLogger.Error(revertErr, "Error reverting GitHub webhook secret")
+		}
+		return 0, err
+	}
+
+	now := metav1.Now()
+	instance.Status.WebhookSecretRotationTimestamp = &now
+	instance.Status.WebhookSecretRotationRequest = request
+	if err := r.updateStarterKitStatus(ctx, instance); err != nil {
+		return 0, err
+	}
+	reqLogger.Info("Webhook secret rotated successfully")
+
+	if periodic {
+		return interval.Duration, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	appsv1 "github.com/openshift/api/apps/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// knativeServiceGVK is the Knative Serving Service kind
+var knativeServiceGVK = schema.GroupVersionKind{Group: "serving.knative.dev", Version: "v1", Kind: "Service"}
+
+// knativeRequeueDelay is how long to wait before checking on a Knative Service that has no image or URL yet
+const knativeRequeueDelay = 15 * time.Second
+
+// Create a new Knative Service running the specified image
+func newKnativeServiceForCR(cr *devxv1alpha1.StarterKit, image string) (*unstructured.Unstructured, error) {
+	labels := map[string]string{
+		"app":  cr.Name,
+		"name": cr.Name,
+		"devx": "",
+	}
+	annotations := map[string]string{}
+	if cr.Spec.Knative.MinScale != nil {
+		annotations["autoscaling.knative.dev/min-scale"] = strconv.Itoa(int(*cr.Spec.Knative.MinScale))
+	}
+	if cr.Spec.Knative.MaxScale != nil {
+		annotations["autoscaling.knative.dev/max-scale"] = strconv.Itoa(int(*cr.Spec.Knative.MaxScale))
+	}
+	if cr.Spec.Knative.ScaleToZeroPodRetentionPeriod != "" {
+		annotations["autoscaling.knative.dev/scale-to-zero-pod-retention-period"] = cr.Spec.Knative.ScaleToZeroPodRetentionPeriod
+	}
+	if redeploy := cr.GetAnnotations()[devxv1alpha1.RedeployAnnotation]; redeploy != "" {
+		annotations[devxv1alpha1.RedeployAnnotation] = redeploy
+	}
+
+	// Knative only allows a single port per container, which receives all requests
+	routePort := routePortForCR(cr)
+	var port *devxv1alpha1.StarterKitSpecPort
+	ports := portsForCR(cr)
+	for i := range ports {
+		if ports[i].Name == routePort {
+			port = &ports[i]
+		}
+	}
+	if port == nil {
+		return nil, fmt.Errorf("route port %q does not match any port of the application", routePort)
+	}
+	container := corev1.Container{
+		Name:  cr.Name,
+		Image: image,
+		Ports: []corev1.ContainerPort{
+			{
+				ContainerPort: port.Port,
+				Protocol:      port.Protocol,
+			},
+		},
+		Env:            cr.Spec.Options.Env,
+		LivenessProbe:  cr.Spec.Options.LivenessProbe,
+		ReadinessProbe: cr.Spec.Options.ReadinessProbe,
+	}
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{container}}
+	injectBindings(podSpec, cr.Name, cr)
+	podSpecObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(podSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	templateSpec := map[string]interface{}{
+		"containers": podSpecObj["containers"],
+	}
+	if volumes, ok := podSpecObj["volumes"]; ok {
+		templateSpec["volumes"] = volumes
+	}
+	if cr.Spec.Knative.ContainerConcurrency != nil {
+		templateSpec["containerConcurrency"] = *cr.Spec.Knative.ContainerConcurrency
+	}
+
+	service := &unstructured.Unstructured{}
+	service.SetGroupVersionKind(knativeServiceGVK)
+	service.SetName(cr.Name)
+	service.SetNamespace(cr.Namespace)
+	service.SetLabels(labels)
+	service.SetAnnotations(map[string]string{
+		"app.openshift.io/vcs-uri": cr.Status.TargetRepo,
+	})
+	// Empty annotations are dropped by the API server, so they are omitted to compare equal with the stored template
+	templateMetadata := map[string]interface{}{
+		"labels": toInterfaceMap(labels),
+	}
+	if len(annotations) > 0 {
+		templateMetadata["annotations"] = toInterfaceMap(annotations)
+	}
+	service.Object["spec"] = map[string]interface{}{
+		"template": map[string]interface{}{
+			"metadata": templateMetadata,
+			"spec":     templateSpec,
+		},
+	}
+	return service, nil
+}
+
+// Creates or updates the Knative Service of the specified StarterKit once an image has been built for it. Each
+// newly built image is rolled out as a new revision.
+func (r *StarterKitReconciler) reconcileKnativeService(ctx context.Context, instance *devxv1alpha1.StarterKit, result *ctrl.Result, reqLogger logr.Logger) error {
+	reqLogger.Info("Configuring Knative Service")
+
+	// The Knative Service can only be created once the first image has been built
+	image := &imagev1.ImageStream{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, image)
+	if err != nil {
+		reqLogger.Error(err, "Error fetching ImageStream")
+		return err
+	}
+	digest := latestImageDigest(image, "latest")
+	if instance.Spec.PinnedImage != "" {
+		digest = instance.Spec.PinnedImage
+	}
+	if digest == "" || image.Status.DockerImageRepository == "" {
+		reqLogger.Info("Waiting for the first image to be built")
+		return nil
+	}
+
+	service, err := newKnativeServiceForCR(instance, image.Status.DockerImageRepository+"@"+digest)
+	if err != nil {
+		reqLogger.Error(err, "Error generating Knative Service")
+		return err
+	}
+
+	// Set StarterKit instance as the owner and controller
+	if err := controllerutil.SetControllerReference(instance, service, r.Scheme); err != nil {
+		reqLogger.Error(err, "Error setting Knative Service on StarterKit")
+		return err
+	}
+
+	// Check if this Knative Service already exists
+	foundService := &unstructured.Unstructured{}
+	foundService.SetGroupVersionKind(knativeServiceGVK)
+	err = r.Client.Get(ctx, types.NamespacedName{Name: service.GetName(), Namespace: service.GetNamespace()}, foundService)
+	if err != nil && errors.IsNotFound(err) {
+		reqLogger.Info("Creating a new Knative Service", "Service.Namespace", service.GetNamespace(), "Service.Name", service.GetName())
+		err = r.Client.Create(ctx, service)
+		if err != nil {
+			reqLogger.Error(err, "Error creating Knative Service")
+			return err
+		}
+
+		// Knative Service created successfully
+		reqLogger.Info("Knative Service created successfully")
+		foundService = service
+	} else if err != nil {
+		reqLogger.Error(err, "Error fetching Knative Service")
+		return err
+	} else if knativeServiceChanged(foundService, service) {
+		reqLogger.Info("Updating Knative Service", "Service.Namespace", foundService.GetNamespace(), "Service.Name", foundService.GetName())
+		template, _, _ := unstructured.NestedMap(service.Object, "spec", "template")
+		if err := unstructured.SetNestedMap(foundService.Object, template, "spec", "template"); err != nil {
+			return err
+		}
+		err = r.Client.Update(ctx, foundService)
+		if err != nil {
+			reqLogger.Error(err, "Error updating Knative Service")
+			return err
+		}
+	}
+
+	// Knative Services are not watched, so check back until the URL has been assigned
+	if url, _, _ := unstructured.NestedString(foundService.Object, "status", "url"); url == "" {
+		requeueAfter(result, knativeRequeueDelay)
+	}
+	return nil
+}
+
+// Returns true if the revision template fields managed by the StarterKit differ between the found and desired
+// Knative Service. Knative defaults the remaining template fields, so they are not compared.
+func knativeServiceChanged(found *unstructured.Unstructured, desired *unstructured.Unstructured) bool {
+	paths := [][]string{
+		{"spec", "template", "metadata", "annotations"},
+		{"spec", "template", "spec", "containerConcurrency"},
+		{"spec", "template", "spec", "volumes"},
+	}
+	for _, path := range paths {
+		foundValue, _, _ := unstructured.NestedFieldNoCopy(found.Object, path...)
+		desiredValue, _, _ := unstructured.NestedFieldNoCopy(desired.Object, path...)
+		if !equality.Semantic.DeepEqual(foundValue, desiredValue) {
+			return true
+		}
+	}
+
+	foundContainers, _, _ := unstructured.NestedSlice(found.Object, "spec", "template", "spec", "containers")
+	desiredContainers, _, _ := unstructured.NestedSlice(desired.Object, "spec", "template", "spec", "containers")
+	if len(foundContainers) != len(desiredContainers) {
+		return true
+	}
+	for i := range desiredContainers {
+		foundContainer, _ := foundContainers[i].(map[string]interface{})
+		desiredContainer, _ := desiredContainers[i].(map[string]interface{})
+		for _, field := range []string{"image", "env", "envFrom", "ports", "volumeMounts"} {
+			if !equality.Semantic.DeepEqual(foundContainer[field], desiredContainer[field]) {
+				return true
+			}
+		}
+		// Knative fills in probes and resources that are not set, so only the values set by the StarterKit are
+		// compared. Removing a probe therefore does not update the Knative Service.
+		for _, field := range []string{"livenessProbe", "readinessProbe", "resources"} {
+			if !containsFields(foundContainer[field], desiredContainer[field]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns true if every field set in the desired unstructured value has the same value in the found one.
+func containsFields(found interface{}, desired interface{}) bool {
+	if desired == nil {
+		return true
+	}
+	desiredMap, ok := desired.(map[string]interface{})
+	if !ok {
+		return equality.Semantic.DeepEqual(found, desired)
+	}
+	foundMap, ok := found.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for k, v := range desiredMap {
+		if !containsFields(foundMap[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the URL assigned to the Knative Service of the specified StarterKit, or an empty string if it does not
+// exist or has not been assigned a URL yet.
+func (r *StarterKitReconciler) knativeServiceURL(ctx context.Context, instance *devxv1alpha1.StarterKit) (string, error) {
+	service := &unstructured.Unstructured{}
+	service.SetGroupVersionKind(knativeServiceGVK)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, service)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	url, _, err := unstructured.NestedString(service.Object, "status", "url")
+	return url, err
+}
+
+// Converts a string map into the representation used by unstructured objects.
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// Deletes the resources of the runtime the specified StarterKit does not use, in case it was switched over from the
+// DeploymentConfig runtime to Knative or back.
+func (r *StarterKitReconciler) deleteUnusedRuntime(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	var unused []client.Object
+	if instance.Spec.Runtime == devxv1alpha1.RuntimeKnative {
+		unused = append(unused, &appsv1.DeploymentConfig{}, &corev1.Service{}, &routev1.Route{}, emptyHTTPRoute(), emptyCertificate())
+	} else {
+		service := &unstructured.Unstructured{}
+		service.SetGroupVersionKind(knativeServiceGVK)
+		unused = append(unused, service)
+	}
+	for _, obj := range unused {
+		if err := r.deleteOwned(ctx, instance, obj, reqLogger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestKnativeServiceChanged(t *testing.T) {
+	newService := func(modify func(cr *devxv1alpha1.StarterKit)) *unstructured.Unstructured {
+		cr := &devxv1alpha1.StarterKit{ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "dev"}}
+		modify(cr)
+		service, err := newKnativeServiceForCR(cr, "registry/my-app@sha256:1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return service
+	}
+	probe := &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/health"}}}
+	// Knative fills in the probe handler port and thresholds
+	defaulted := func(found *unstructured.Unstructured) {
+		containers, _, _ := unstructured.NestedSlice(found.Object, "spec", "template", "spec", "containers")
+		container := containers[0].(map[string]interface{})
+		container["readinessProbe"] = map[string]interface{}{
+			"httpGet":          map[string]interface{}{"path": "/health", "port": int64(0)},
+			"successThreshold": int64(1),
+		}
+		container["resources"] = map[string]interface{}{}
+		_ = unstructured.SetNestedSlice(found.Object, containers, "spec", "template", "spec", "containers")
+	}
+
+	tests := []struct {
+		name     string
+		found    func(cr *devxv1alpha1.StarterKit)
+		desired  func(cr *devxv1alpha1.StarterKit)
+		defaults bool
+		changed  bool
+	}{
+		{
+			name:    "unchanged",
+			found:   func(cr *devxv1alpha1.StarterKit) {},
+			desired: func(cr *devxv1alpha1.StarterKit) {},
+		},
+		{
+			name:  "environment changed",
+			found: func(cr *devxv1alpha1.StarterKit) {},
+			desired: func(cr *devxv1alpha1.StarterKit) {
+				cr.Spec.Options.Env = []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}
+			},
+			changed: true,
+		},
+		{
+			name:    "readiness probe added",
+			found:   func(cr *devxv1alpha1.StarterKit) {},
+			desired: func(cr *devxv1alpha1.StarterKit) { cr.Spec.Options.ReadinessProbe = probe },
+			changed: true,
+		},
+		{
+			name:  "liveness probe changed",
+			found: func(cr *devxv1alpha1.StarterKit) { cr.Spec.Options.LivenessProbe = probe },
+			desired: func(cr *devxv1alpha1.StarterKit) {
+				cr.Spec.Options.LivenessProbe = &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/live", Port: intstr.FromInt(8080)}}}
+			},
+			changed: true,
+		},
+		{
+			name:     "probe and resources defaulted by Knative",
+			found:    func(cr *devxv1alpha1.StarterKit) {},
+			desired:  func(cr *devxv1alpha1.StarterKit) { cr.Spec.Options.ReadinessProbe = probe },
+			defaults: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := newService(tt.found)
+			if tt.defaults {
+				defaulted(found)
+			}
+			if changed := knativeServiceChanged(found, newService(tt.desired)); changed != tt.changed {
+				t.Errorf("knativeServiceChanged() = %v, expected %v", changed, tt.changed)
+			}
+		})
+	}
+}
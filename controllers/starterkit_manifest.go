@@ -0,0 +1,106 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Reads the manifest of the specified template repo. An empty manifest is returned if the template repo has no
+// manifest or the manifest cannot be parsed, so that the StarterKit falls back to the regular defaults.
+func fetchManifest(ctx context.Context, githubClient *github.Client, owner string, repo string, reqLogger logr.Logger) (*devxv1alpha1.StarterKitManifest, error) {
+	manifest := &devxv1alpha1.StarterKitManifest{}
+	file, _, resp, err := githubClient.Repositories.GetContents(ctx, owner, repo, devxv1alpha1.ManifestPath, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			reqLogger.Info("Template repo has no manifest", "Template.Owner", owner, "Template.Repo", repo)
+			return manifest, nil
+		}
+		return nil, err
+	}
+	if file == nil {
+		// The manifest path is a directory
+		return manifest, nil
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal([]byte(content), manifest); err != nil {
+		reqLogger.Error(err, "Ignoring invalid manifest", "Template.Owner", owner, "Template.Repo", repo)
+		return &devxv1alpha1.StarterKitManifest{}, nil
+	}
+	reqLogger.Info("Read manifest of template repo", "Template.Owner", owner, "Template.Repo", repo)
+	return manifest, nil
+}
+
+// Fills the fields of the StarterKit spec that are neither set on the StarterKit nor by its template with the
+// values of the manifest read from the template repo.
+func mergeManifest(spec *devxv1alpha1.StarterKitSpec, manifest *devxv1alpha1.StarterKitManifest) {
+	if manifest == nil {
+		return
+	}
+	if spec.Options.Port == 0 && len(spec.Options.Ports) == 0 {
+		spec.Options.Port = manifest.Port
+	}
+	spec.Options.Env = mergeEnv(spec.Options.Env, manifest.Env)
+	if spec.Options.LivenessProbe == nil && manifest.LivenessProbe != nil {
+		spec.Options.LivenessProbe = manifest.LivenessProbe.DeepCopy()
+	}
+	if spec.Options.ReadinessProbe == nil && manifest.ReadinessProbe != nil {
+		spec.Options.ReadinessProbe = manifest.ReadinessProbe.DeepCopy()
+	}
+	if spec.Options.BuildStrategy == nil && manifest.BuildStrategy != nil {
+		spec.Options.BuildStrategy = manifest.BuildStrategy.DeepCopy()
+	}
+	for _, name := range manifest.Bindings {
+		found := false
+		for _, b := range spec.Bindings {
+			if b.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			spec.Bindings = append(spec.Bindings, devxv1alpha1.StarterKitSpecBinding{Name: name})
+		}
+	}
+}
+
+// Appends the environment variables of defaults that are not set in env.
+func mergeEnv(env []corev1.EnvVar, defaults []corev1.EnvVar) []corev1.EnvVar {
+	for _, v := range defaults {
+		found := false
+		for _, e := range env {
+			if e.Name == v.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			env = append(env, v)
+		}
+	}
+	return env
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestMergeManifest(t *testing.T) {
+	probe := &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/health"}}}
+	manifest := &devxv1alpha1.StarterKitManifest{
+		Port:           8080,
+		Env:            []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}, {Name: "PORT", Value: "8080"}},
+		LivenessProbe:  probe,
+		ReadinessProbe: probe,
+		BuildStrategy:  &devxv1alpha1.StarterKitSpecBuildStrategy{Type: devxv1alpha1.BuildStrategyDocker},
+		Bindings:       []string{"db", "cache"},
+	}
+
+	tests := []struct {
+		name     string
+		spec     devxv1alpha1.StarterKitSpec
+		manifest *devxv1alpha1.StarterKitManifest
+		want     devxv1alpha1.StarterKitSpec
+	}{
+		{
+			name: "no manifest",
+			spec: devxv1alpha1.StarterKitSpec{Options: devxv1alpha1.StarterKitSpecOptions{Port: 3000}},
+			want: devxv1alpha1.StarterKitSpec{Options: devxv1alpha1.StarterKitSpecOptions{Port: 3000}},
+		},
+		{
+			name:     "empty spec",
+			manifest: manifest,
+			want: devxv1alpha1.StarterKitSpec{
+				Options: devxv1alpha1.StarterKitSpecOptions{
+					Port:           8080,
+					Env:            manifest.Env,
+					LivenessProbe:  probe,
+					ReadinessProbe: probe,
+					BuildStrategy:  manifest.BuildStrategy,
+				},
+				Bindings: []devxv1alpha1.StarterKitSpecBinding{{Name: "db"}, {Name: "cache"}},
+			},
+		},
+		{
+			name: "fields set on the StarterKit",
+			spec: devxv1alpha1.StarterKitSpec{
+				Options: devxv1alpha1.StarterKitSpecOptions{
+					Ports:         []devxv1alpha1.StarterKitSpecPort{{Name: "http", Port: 9080}},
+					Env:           []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+					LivenessProbe: &corev1.Probe{},
+					BuildStrategy: &devxv1alpha1.StarterKitSpecBuildStrategy{Type: devxv1alpha1.BuildStrategySource},
+				},
+				Bindings: []devxv1alpha1.StarterKitSpecBinding{{Name: "db", MountPath: "/bindings/db"}},
+			},
+			manifest: manifest,
+			want: devxv1alpha1.StarterKitSpec{
+				Options: devxv1alpha1.StarterKitSpecOptions{
+					Ports:          []devxv1alpha1.StarterKitSpecPort{{Name: "http", Port: 9080}},
+					Env:            []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}, {Name: "PORT", Value: "8080"}},
+					LivenessProbe:  &corev1.Probe{},
+					ReadinessProbe: probe,
+					BuildStrategy:  &devxv1alpha1.StarterKitSpecBuildStrategy{Type: devxv1alpha1.BuildStrategySource},
+				},
+				Bindings: []devxv1alpha1.StarterKitSpecBinding{{Name: "db", MountPath: "/bindings/db"}, {Name: "cache"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec.DeepCopy()
+			mergeManifest(spec, tt.manifest)
+			if !equality.Semantic.DeepEqual(spec, &tt.want) {
+				t.Errorf("mergeManifest() = %+v, want %+v", spec, &tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		status  int
+		want    *devxv1alpha1.StarterKitManifest
+		wantErr bool
+	}{
+		{name: "manifest", content: "port: 8080\nbindings: [db]\n", want: &devxv1alpha1.StarterKitManifest{Port: 8080, Bindings: []string{"db"}}},
+		{name: "invalid manifest", content: "port: [8080]\n", want: &devxv1alpha1.StarterKitManifest{}},
+		{name: "no manifest", status: http.StatusNotFound, want: &devxv1alpha1.StarterKitManifest{}},
+		{name: "GitHub error", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if tt.status != 0 {
+					rw.WriteHeader(tt.status)
+					return
+				}
+				fmt.Fprintf(rw, `{"type":"file","encoding":"base64","content":%q}`, base64.StdEncoding.EncodeToString([]byte(tt.content)))
+			}))
+			defer server.Close()
+			githubClient := github.NewClient(nil)
+			githubClient.BaseURL, _ = url.Parse(server.URL + "/")
+
+			manifest, err := fetchManifest(context.Background(), githubClient, "IBM", "java-spring-app", log.Log)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchManifest() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !equality.Semantic.DeepEqual(manifest, tt.want) {
+				t.Errorf("fetchManifest() = %+v, want %+v", manifest, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Create a new NetworkPolicy. Ingress to the application ports is allowed from the router namespaces and the peers
+// listed on the StarterKit, and egress is restricted to the listed rules if there are any. The policy applies to the
+// pods of the application and of its pull request previews.
+func newNetworkPolicyForCR(cr *devxv1alpha1.StarterKit) *networkingv1.NetworkPolicy {
+	labels := map[string]string{
+		"app":  cr.Name,
+		"devx": "",
+	}
+	names := []string{cr.Name}
+	for _, pr := range cr.Status.PullRequests {
+		names = append(names, previewName(cr, pr.Number))
+	}
+	selector := metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "name", Operator: metav1.LabelSelectorOpIn, Values: names},
+		},
+	}
+	policy := cr.Spec.NetworkPolicy
+
+	routerSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"network.openshift.io/policy-group": "ingress",
+		},
+	}
+	if policy.RouterNamespaceSelector != nil {
+		routerSelector = policy.RouterNamespaceSelector
+	}
+	from := []networkingv1.NetworkPolicyPeer{
+		{NamespaceSelector: routerSelector},
+	}
+	if gatewayAPI := cr.Spec.Exposure.GatewayAPI; gatewayAPI != nil && gatewayAPI.GatewayRef.Namespace != "" {
+		from = append(from, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"kubernetes.io/metadata.name": gatewayAPI.GatewayRef.Namespace,
+				},
+			},
+		})
+	}
+	from = append(from, policy.From...)
+
+	var ports []networkingv1.NetworkPolicyPort
+	for _, p := range portsForCR(cr) {
+		protocol := p.Protocol
+		port := intstr.FromInt(int(p.Port))
+		ports = append(ports, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &port,
+		})
+	}
+
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			Ports: ports,
+			From:  from,
+		},
+	}
+	if cr.Spec.Runtime == devxv1alpha1.RuntimeKnative {
+		// Requests to Knative Services are proxied by the activator and ingress in the Knative system namespaces to
+		// the queue-proxy sidecar, which also serves the autoscaler metrics, so no port restriction applies to them
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"knative.openshift.io/system-namespace": "true",
+						},
+					},
+				},
+			},
+		})
+	}
+
+	policyTypes := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	if len(policy.Egress) > 0 {
+		policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+	}
+
+	return &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "k8s.io/api/networking/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: selector,
+			Ingress:     ingress,
+			Egress:      policy.Egress,
+			PolicyTypes: policyTypes,
+		},
+	}
+}
+
+// Creates or updates the NetworkPolicy of the specified StarterKit, or deletes it when the StarterKit no longer
+// asks for one.
+func (r *StarterKitReconciler) reconcileNetworkPolicy(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	foundPolicy := &networkingv1.NetworkPolicy{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, foundPolicy)
+	if err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "Error fetching NetworkPolicy")
+		return err
+	}
+	found := err == nil
+
+	if instance.Spec.NetworkPolicy == nil {
+		if found && metav1.IsControlledBy(foundPolicy, instance) {
+			reqLogger.Info("Deleting NetworkPolicy", "NetworkPolicy.Namespace", foundPolicy.Namespace, "NetworkPolicy.Name", foundPolicy.Name)
+			if err := r.Client.Delete(ctx, foundPolicy); err != nil && !errors.IsNotFound(err) {
+				reqLogger.Error(err, "Error deleting NetworkPolicy")
+				return err
+			}
+		}
+		return nil
+	}
+
+	reqLogger.Info("Configuring NetworkPolicy")
+	policy := newNetworkPolicyForCR(instance)
+
+	// Set StarterKit instance as the owner and controller
+	if err := controllerutil.SetControllerReference(instance, policy, r.Scheme); err != nil {
+		reqLogger.Error(err, "Error setting NetworkPolicy on StarterKit")
+		return err
+	}
+
+	if !found {
+		reqLogger.Info("Creating a new NetworkPolicy", "NetworkPolicy.Namespace", policy.Namespace, "NetworkPolicy.Name", policy.Name)
+		err = r.Client.Create(ctx, policy)
+		if err != nil {
+			reqLogger.Error(err, "Error creating NetworkPolicy")
+			return err
+		}
+
+		// NetworkPolicy created successfully
+		reqLogger.Info("NetworkPolicy created successfully")
+	} else if !equality.Semantic.DeepEqual(foundPolicy.Spec, policy.Spec) {
+		reqLogger.Info("Updating NetworkPolicy", "NetworkPolicy.Namespace", foundPolicy.Namespace, "NetworkPolicy.Name", foundPolicy.Name)
+		foundPolicy.Spec = policy.Spec
+		err = r.Client.Update(ctx, foundPolicy)
+		if err != nil {
+			reqLogger.Error(err, "Error updating NetworkPolicy")
+			return err
+		}
+	} else {
+		// NetworkPolicy already exists - don't requeue
+		reqLogger.Info("Skip reconcile: NetworkPolicy already exists", "NetworkPolicy.Namespace", foundPolicy.Namespace, "NetworkPolicy.Name", foundPolicy.Name)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+)
+
+// initializationCommitMessage is the message of the commit applying the parameters to the target repo
+const initializationCommitMessage = "Initialize from template"
+
+// targetRepoPollInterval is the time to wait for GitHub to generate the target repo from the template repo
+const targetRepoPollInterval = 5 * time.Second
+
+// defaultParameterFiles are the files the parameters are applied to, unless the manifest of the template repo lists
+// other files
+var defaultParameterFiles = []string{"package.json", "pom.xml", "README.md"}
+
+// Returns the files of the target repo of the specified StarterKit containing parameter placeholders.
+func parameterFilesForCR(cr *devxv1alpha1.StarterKit) []string {
+	if cr.Status.Manifest != nil && len(cr.Status.Manifest.ParameterFiles) > 0 {
+		return cr.Status.Manifest.ParameterFiles
+	}
+	return defaultParameterFiles
+}
+
+// Replaces the "{{starterkit.<key>}}" placeholders in the specified content with the values of the parameters.
+func applyParameters(content string, parameters map[string]string) string {
+	for key, value := range parameters {
+		content = strings.ReplaceAll(content, "{{starterkit."+key+"}}", value)
+	}
+	return content
+}
+
+// Returns true if the specified GitHub response indicates that the target repo is still being generated from the
+// template repo.
+func isRepoNotGenerated(resp *github.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusConflict)
+}
+
+// Applies the parameters of the specified StarterKit to the files of its target repo, in a single commit on top of
+// the commit generated from the template repo. This is only done for a target repo created with parameters, which
+// is recorded in the status when the repo is created. Returns the time to wait before trying again if GitHub has not
+// generated the target repo yet.
+func (r *StarterKitReconciler) reconcileParameters(ctx context.Context, githubClient *github.Client, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) (time.Duration, error) {
+	if !instance.Status.ParametersPending {
+		return 0, nil
+	}
+	owner := instance.Spec.TemplateRepo.Owner
+	repo := instance.Spec.TemplateRepo.Name
+
+	// Find the commit generated from the template repo
+	targetRepo, _, err := githubClient.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return 0, err
+	}
+	ref := "heads/" + targetRepo.GetDefaultBranch()
+	head, resp, err := githubClient.Git.GetRef(ctx, owner, repo, ref)
+	if err != nil {
+		if isRepoNotGenerated(resp) {
+			reqLogger.Info("Waiting for GitHub to generate the target repo", "Repo.Owner", owner, "Repo.Name", repo)
+			return targetRepoPollInterval, nil
+		}
+		return 0, err
+	}
+	parent, _, err := githubClient.Git.GetCommit(ctx, owner, repo, head.GetObject().GetSHA())
+	if err != nil {
+		return 0, err
+	}
+
+	// Apply the parameters to the files
+	var entries []*github.TreeEntry
+	for _, path := range parameterFilesForCR(instance) {
+		file, _, resp, err := githubClient.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: parent.GetSHA()})
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return 0, err
+		}
+		if file == nil {
+			// The path is a directory
+			continue
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			return 0, err
+		}
+		if applied := applyParameters(content, instance.Spec.TemplateRepo.Parameters); applied != content {
+			entries = append(entries, &github.TreeEntry{
+				Path:    github.String(path),
+				Mode:    github.String("100644"),
+				Type:    github.String("blob"),
+				Content: github.String(applied),
+			})
+		}
+	}
+
+	commitSHA := parent.GetSHA()
+	if len(entries) > 0 {
+		tree, _, err := githubClient.Git.CreateTree(ctx, owner, repo, parent.GetTree().GetSHA(), entries)
+		if err != nil {
+			return 0, err
+		}
+		commit, _, err := githubClient.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+			Message: github.String(initializationCommitMessage),
+			Tree:    tree,
+			Parents: []*github.Commit{{SHA: parent.SHA}},
+		})
+		if err != nil {
+			return 0, err
+		}
+		head.Object.SHA = commit.SHA
+		if _, _, err := githubClient.Git.UpdateRef(ctx, owner, repo, head, false); err != nil {
+			return 0, err
+		}
+		commitSHA = commit.GetSHA()
+		reqLogger.Info("Parameters applied to target repo", "Repo.Owner", owner, "Repo.Name", repo, "Commit", commitSHA)
+	} else {
+		reqLogger.Info("No parameter placeholders found in target repo", "Repo.Owner", owner, "Repo.Name", repo)
+	}
+
+	instance.Status.InitializationCommit = commitSHA
+	instance.Status.ParametersPending = false
+	return 0, r.updateStarterKitStatus(ctx, instance)
+}
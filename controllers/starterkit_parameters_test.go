@@ -0,0 +1,112 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+)
+
+func TestApplyParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		parameters map[string]string
+		want       string
+	}{
+		{
+			name:       "placeholders",
+			content:    `{"name": "{{starterkit.name}}", "description": "{{starterkit.description}}"}`,
+			parameters: map[string]string{"name": "my-app", "description": "My app"},
+			want:       `{"name": "my-app", "description": "My app"}`,
+		},
+		{
+			name:       "repeated placeholder",
+			content:    "# {{starterkit.name}}\n\nRun {{starterkit.name}} with npm start",
+			parameters: map[string]string{"name": "my-app"},
+			want:       "# my-app\n\nRun my-app with npm start",
+		},
+		{
+			name:       "placeholder without parameter",
+			content:    "{{starterkit.name}} {{starterkit.version}}",
+			parameters: map[string]string{"name": "my-app"},
+			want:       "my-app {{starterkit.version}}",
+		},
+		{
+			name:       "other templating syntax",
+			content:    "{{name}} {{ starterkit.name }}",
+			parameters: map[string]string{"name": "my-app"},
+			want:       "{{name}} {{ starterkit.name }}",
+		},
+		{
+			name:    "no parameters",
+			content: "{{starterkit.name}}",
+			want:    "{{starterkit.name}}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyParameters(tt.content, tt.parameters); got != tt.want {
+				t.Errorf("applyParameters() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParameterFilesForCR(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest *devxv1alpha1.StarterKitManifest
+		want     []string
+	}{
+		{name: "no manifest", want: defaultParameterFiles},
+		{name: "manifest without parameter files", manifest: &devxv1alpha1.StarterKitManifest{Port: 8080}, want: defaultParameterFiles},
+		{name: "manifest with parameter files", manifest: &devxv1alpha1.StarterKitManifest{ParameterFiles: []string{"app.yaml"}}, want: []string{"app.yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &devxv1alpha1.StarterKit{Status: devxv1alpha1.StarterKitStatus{Manifest: tt.manifest}}
+			if got := parameterFilesForCR(cr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parameterFilesForCR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRepoNotGenerated(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *github.Response
+		want bool
+	}{
+		{name: "no response"},
+		{name: "not found", resp: &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}, want: true},
+		{name: "conflict", resp: &github.Response{Response: &http.Response{StatusCode: http.StatusConflict}}, want: true},
+		{name: "server error", resp: &github.Response{Response: &http.Response{StatusCode: http.StatusInternalServerError}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRepoNotGenerated(tt.resp); got != tt.want {
+				t.Errorf("isRepoNotGenerated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
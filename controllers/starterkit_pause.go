@@ -0,0 +1,131 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Pauses or resumes the rollouts of all DeploymentConfigs of the specified StarterKit, including those of its
+// environments, and the GitHub webhook trigger of its BuildConfig according to its spec, and reports the result in the
+// Paused condition.
+func (r *StarterKitReconciler) reconcilePause(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	paused := instance.Spec.Paused
+
+	deployments := &appsv1.DeploymentConfigList{}
+	if err := r.Client.List(ctx, deployments, client.InNamespace(instance.Namespace), client.MatchingLabels{"app": instance.Name}); err != nil {
+		reqLogger.Error(err, "Error listing Deployments")
+		return err
+	}
+	var owned []*appsv1.DeploymentConfig
+	for i := range deployments.Items {
+		if metav1.IsControlledBy(&deployments.Items[i], instance) {
+			owned = append(owned, &deployments.Items[i])
+		}
+	}
+	// The DeploymentConfigs of the environments live in other namespaces and cannot be owned by the StarterKit
+	environments := &appsv1.DeploymentConfigList{}
+	if err := r.Client.List(ctx, environments, client.MatchingLabels{starterKitNameLabel: instance.Name, starterKitNamespaceLabel: instance.Namespace}); err != nil {
+		reqLogger.Error(err, "Error listing environment Deployments")
+		return err
+	}
+	for i := range environments.Items {
+		owned = append(owned, &environments.Items[i])
+	}
+	for _, dc := range owned {
+		if dc.Spec.Paused == paused {
+			continue
+		}
+		reqLogger.Info("Updating Deployment", "Deployment.Namespace", dc.Namespace, "Deployment.Name", dc.Name, "paused", paused)
+		dc.Spec.Paused = paused
+		if err := r.Client.Update(ctx, dc); err != nil {
+			reqLogger.Error(err, "Error updating Deployment")
+			return err
+		}
+	}
+
+	build := &buildv1.BuildConfig{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, build)
+	if err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "Error fetching Build")
+		return err
+	}
+	if err == nil && setGitHubTrigger(build, newBuildForCR(instance), !paused) {
+		reqLogger.Info("Updating Build", "Build.Name", build.Name, "paused", paused)
+		if err := r.Client.Update(ctx, build); err != nil {
+			reqLogger.Error(err, "Error updating Build")
+			return err
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               devxv1alpha1.ConditionPaused,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Reconciling",
+		Message:            "The StarterKit is reconciled",
+		ObservedGeneration: instance.Generation,
+	}
+	if paused {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Paused"
+		condition.Message = "Rollouts, webhook builds and reconciliation are paused"
+	}
+	existing := meta.FindStatusCondition(instance.Status.Conditions, condition.Type)
+	if existing != nil && existing.Status == condition.Status && existing.Reason == condition.Reason && existing.ObservedGeneration == condition.ObservedGeneration {
+		return nil
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+	return r.updateStarterKitStatus(ctx, instance)
+}
+
+// Adds the GitHub webhook trigger of the desired BuildConfig to the found BuildConfig if enabled, or removes it
+// otherwise. Returns true if the found BuildConfig was changed and needs to be updated.
+func setGitHubTrigger(found *buildv1.BuildConfig, desired *buildv1.BuildConfig, enabled bool) bool {
+	var triggers []buildv1.BuildTriggerPolicy
+	present := false
+	for _, t := range found.Spec.Triggers {
+		if t.Type == buildv1.GitHubWebHookBuildTriggerType {
+			present = true
+			if !enabled {
+				continue
+			}
+		}
+		triggers = append(triggers, t)
+	}
+	if present == enabled {
+		return false
+	}
+	if enabled {
+		for _, t := range desired.Spec.Triggers {
+			if t.Type == buildv1.GitHubWebHookBuildTriggerType {
+				triggers = append(triggers, t)
+			}
+		}
+	}
+	found.Spec.Triggers = triggers
+	return true
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// pullRequestLabel is set on the resources of a preview environment to the number of its pull request
+const pullRequestLabel = "devx.ibm.com/pull-request"
+
+// defaultPreviewLabel is the pull request label opting in untrusted pull requests when the StarterKit names none
+const defaultPreviewLabel = "preview"
+
+// Returns why the pull request of the specified event is not previewed, or an empty string if it is. Pull requests
+// are only previewed if their head is in the target repo, if their author is an owner, member or collaborator of the
+// target repo, or if a maintainer applied the preview label, since a preview runs their code in the cluster.
+func previewSkipReason(cr *devxv1alpha1.StarterKit, event *github.PullRequestEvent) string {
+	pr := event.GetPullRequest()
+	if strings.EqualFold(pr.GetHead().GetRepo().GetFullName(), event.GetRepo().GetFullName()) {
+		return ""
+	}
+	switch pr.GetAuthorAssociation() {
+	case "OWNER", "MEMBER", "COLLABORATOR":
+		return ""
+	}
+	label := cr.Spec.Previews.Label
+	if label == "" {
+		label = defaultPreviewLabel
+	}
+	for _, l := range pr.Labels {
+		if l.GetName() == label {
+			return ""
+		}
+	}
+	return fmt.Sprintf("The pull request comes from another repository and its author is not a collaborator; apply the %q label to preview it", label)
+}
+
+// Returns the name of the resources of the preview environment of the specified pull request.
+func previewName(cr *devxv1alpha1.StarterKit, number int) string {
+	return fmt.Sprintf("%s-pr-%d", cr.Name, number)
+}
+
+// Returns the ImageStream tag the specified pull request is built into.
+func previewImageTag(number int) string {
+	return fmt.Sprintf("pr-%d", number)
+}
+
+// Returns a copy of the specified StarterKit describing the preview environment of a pull request, so that the
+// resources of the preview can be derived with the same builders as the ones of the StarterKit. The preview is
+// always exposed on a router generated host.
+func previewCR(cr *devxv1alpha1.StarterKit, number int) *devxv1alpha1.StarterKit {
+	preview := cr.DeepCopy()
+	preview.Name = previewName(cr, number)
+	preview.Spec.Route = devxv1alpha1.StarterKitSpecRoute{
+		Termination: string(routev1.TLSTerminationEdge),
+	}
+	return preview
+}
+
+// Labels a resource of a preview environment so that it is attributed to the StarterKit and the pull request.
+func labelPreview(obj metav1.Object, cr *devxv1alpha1.StarterKit, number int) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels["app"] = cr.Name
+	labels[pullRequestLabel] = strconv.Itoa(number)
+	obj.SetLabels(labels)
+}
+
+// Create a new BuildConfig building the head of a pull request into its own ImageStream tag
+func newPreviewBuildForCR(cr *devxv1alpha1.StarterKit, number int) *buildv1.BuildConfig {
+	build := newBuildForCR(previewCR(cr, number))
+	labelPreview(build, cr, number)
+	build.Spec.Source.Git.Ref = fmt.Sprintf("refs/pull/%d/head", number)
+	build.Spec.Output.To.Name = cr.Name + ":" + previewImageTag(number)
+	build.Spec.Triggers = []buildv1.BuildTriggerPolicy{
+		{
+			Type: buildv1.ConfigChangeBuildTriggerType,
+		},
+	}
+	return build
+}
+
+// Create a new DeploymentConfig rolling out the image built from a pull request
+func newPreviewDeploymentForCR(cr *devxv1alpha1.StarterKit, number int) *appsv1.DeploymentConfig {
+	deployment := newDeploymentForCR(previewCR(cr, number))
+	labelPreview(deployment, cr, number)
+	deployment.Spec.Triggers[0].ImageChangeParams.From.Name = cr.Name + ":" + previewImageTag(number)
+	return deployment
+}
+
+// Create a new Service for the preview of a pull request
+func newPreviewServiceForCR(cr *devxv1alpha1.StarterKit, number int) *corev1.Service {
+	service := newServiceForCR(previewCR(cr, number))
+	labelPreview(service, cr, number)
+	return service
+}
+
+// Create a new Route exposing the preview of a pull request
+func newPreviewRouteForCR(cr *devxv1alpha1.StarterKit, number int) *routev1.Route {
+	route := newRouteForCR(previewCR(cr, number), nil)
+	labelPreview(route, cr, number)
+	return route
+}
+
+// Creates the resources of a preview environment for every open pull request recorded on the specified StarterKit that
+// is not skipped, announces the preview URL on each pull request once it is known, and tears down the preview
+// environments of closed and skipped pull requests.
+func (r *StarterKitReconciler) reconcilePreviews(ctx context.Context, githubClient *github.Client, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	open := map[string]bool{}
+	if instance.Spec.Previews.Enabled {
+		changed := false
+		for i := range instance.Status.PullRequests {
+			pr := &instance.Status.PullRequests[i]
+			if pr.SkipReason != "" {
+				continue
+			}
+			open[strconv.Itoa(pr.Number)] = true
+			route, err := r.createPreview(ctx, instance, pr.Number, reqLogger)
+			if err != nil {
+				return err
+			}
+			if route.Spec.Host == "" || pr.CommentID != 0 {
+				continue
+			}
+
+			pr.URL = urlForRoute(route)
+			body := fmt.Sprintf("A preview of this pull request is deployed to %s", pr.URL)
+			comment, _, err := githubClient.Issues.CreateComment(ctx, instance.Spec.TemplateRepo.Owner, instance.Spec.TemplateRepo.Name, pr.Number, &github.IssueComment{Body: &body})
+			if err != nil {
+				reqLogger.Error(err, "Error commenting preview URL on pull request", "number", pr.Number)
+				return err
+			}
+			pr.CommentID = comment.GetID()
+			changed = true
+		}
+		if changed {
+			if err := r.updateStarterKitStatus(ctx, instance); err != nil {
+				return err
+			}
+		}
+	}
+
+	return r.deleteClosedPreviews(ctx, instance, open, reqLogger)
+}
+
+// Creates the resources of the preview environment of the specified pull request if they do not exist yet. Returns
+// the Route of the preview.
+func (r *StarterKitReconciler) createPreview(ctx context.Context, instance *devxv1alpha1.StarterKit, number int, reqLogger logr.Logger) (*routev1.Route, error) {
+	route := newPreviewRouteForCR(instance, number)
+	objects := []client.Object{
+		newPreviewBuildForCR(instance, number),
+		newPreviewDeploymentForCR(instance, number),
+		newPreviewServiceForCR(instance, number),
+		route,
+	}
+	for _, obj := range objects {
+		if err := controllerutil.SetControllerReference(instance, obj, r.Scheme); err != nil {
+			reqLogger.Error(err, "Error setting preview resource on StarterKit")
+			return nil, err
+		}
+		found := obj.DeepCopyObject().(client.Object)
+		err := r.Client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
+		if err != nil && errors.IsNotFound(err) {
+			reqLogger.Info("Creating a new preview resource", "Kind", fmt.Sprintf("%T", obj), "Name", obj.GetName())
+			if err := r.Client.Create(ctx, obj); err != nil {
+				reqLogger.Error(err, "Error creating preview resource")
+				return nil, err
+			}
+			reqLogger.Info("Preview resource created successfully")
+		} else if err != nil {
+			reqLogger.Error(err, "Error fetching preview resource")
+			return nil, err
+		} else if foundRoute, ok := found.(*routev1.Route); ok {
+			route = foundRoute
+		}
+	}
+	return route, nil
+}
+
+// Deletes the preview environments of the specified StarterKit whose pull request is not open.
+func (r *StarterKitReconciler) deleteClosedPreviews(ctx context.Context, instance *devxv1alpha1.StarterKit, open map[string]bool, reqLogger logr.Logger) error {
+	lists := []client.ObjectList{
+		&buildv1.BuildConfigList{},
+		&appsv1.DeploymentConfigList{},
+		&corev1.ServiceList{},
+		&routev1.RouteList{},
+	}
+	closed := map[string]bool{}
+	for _, list := range lists {
+		if err := r.Client.List(ctx, list, client.InNamespace(instance.Namespace), client.MatchingLabels{"app": instance.Name}, client.HasLabels{pullRequestLabel}); err != nil {
+			reqLogger.Error(err, "Error listing preview resources")
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			number := obj.GetLabels()[pullRequestLabel]
+			if open[number] || !metav1.IsControlledBy(obj, instance) {
+				continue
+			}
+			closed[number] = true
+			reqLogger.Info("Deleting preview resource", "Kind", fmt.Sprintf("%T", obj), "Name", obj.GetName())
+			if err := r.Client.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+				reqLogger.Error(err, "Error deleting preview resource")
+				return err
+			}
+		}
+	}
+
+	// The image of a closed pull request is only referenced by the status of the ImageStream
+	for number := range closed {
+		n, _ := strconv.Atoi(number)
+		tag := &imagev1.ImageStreamTag{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      instance.Name + ":" + previewImageTag(n),
+				Namespace: instance.Namespace,
+			},
+		}
+		if err := r.Client.Delete(ctx, tag); err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Error deleting preview ImageStreamTag")
+			return err
+		}
+	}
+	return nil
+}
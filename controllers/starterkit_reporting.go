@@ -0,0 +1,357 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reportedStatusAnnotation records the state last reported to GitHub for a build or rollout
+const reportedStatusAnnotation = "devx.ibm.com/reported-status"
+
+// reportedDeploymentAnnotation records the latest rollout of a DeploymentConfig recorded as a GitHub deployment
+const reportedDeploymentAnnotation = "devx.ibm.com/reported-deployment"
+
+// buildStatusContext identifies the commit statuses of builds
+const buildStatusContext = "starter-kit-operator/build"
+
+// deployStatusContext identifies the commit statuses of rollouts
+const deployStatusContext = "starter-kit-operator/deploy"
+
+// reportingRetryDelay is the delay after which reporting to GitHub is retried when it failed
+const reportingRetryDelay = time.Minute
+
+// GitHub commit status states
+const (
+	commitStatePending = "pending"
+	commitStateSuccess = "success"
+	commitStateFailure = "failure"
+	commitStateError   = "error"
+)
+
+// Returns the commit status state of a build in the specified phase.
+func buildCommitState(phase buildv1.BuildPhase) string {
+	switch phase {
+	case buildv1.BuildPhaseComplete:
+		return commitStateSuccess
+	case buildv1.BuildPhaseFailed:
+		return commitStateFailure
+	case buildv1.BuildPhaseError, buildv1.BuildPhaseCancelled:
+		return commitStateError
+	default:
+		return commitStatePending
+	}
+}
+
+// Returns the commit status state of the latest rollout of the specified DeploymentConfig, or an empty string if the
+// DeploymentConfig has not reported on it yet.
+func rolloutCommitState(dc *appsv1.DeploymentConfig) string {
+	for _, c := range dc.Status.Conditions {
+		if c.Type != appsv1.DeploymentProgressing {
+			continue
+		}
+		switch appsv1.DeploymentConditionReason(c.Reason) {
+		case appsv1.NewReplicationControllerAvailableReason:
+			return commitStateSuccess
+		case appsv1.ProgressDeadlineExceededReason, appsv1.RolloutCancelledReason:
+			return commitStateFailure
+		case appsv1.NewReplicationControllerCreatedReason, appsv1.ReplicationControllerUpdatedReason:
+			return commitStatePending
+		}
+	}
+	return ""
+}
+
+// Returns the commit the specified build was made from, or an empty string if it is not known yet.
+func buildCommit(build *buildv1.Build) string {
+	if build.Spec.Revision == nil || build.Spec.Revision.Git == nil {
+		return ""
+	}
+	return build.Spec.Revision.Git.Commit
+}
+
+// Returns the commit the image rolled out by the specified DeploymentConfig was built from, or an empty string if
+// it was not built by one of the given builds.
+func rolloutCommit(dc *appsv1.DeploymentConfig, builds []buildv1.Build) string {
+	if dc.Spec.Template == nil || len(dc.Spec.Template.Spec.Containers) == 0 {
+		return ""
+	}
+	image := dc.Spec.Template.Spec.Containers[0].Image
+	i := strings.LastIndex(image, "@")
+	if i < 0 {
+		return ""
+	}
+	digest := image[i+1:]
+	for i := range builds {
+		if to := builds[i].Status.Output.To; to != nil && to.ImageDigest == digest {
+			return buildCommit(&builds[i])
+		}
+	}
+	return ""
+}
+
+// Returns the URL of the OpenShift web console, or an empty string if it cannot be determined.
+func (r *StarterKitReconciler) consoleURL(ctx context.Context) string {
+	console := &configv1.Console{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: "cluster"}, console); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(console.Status.ConsoleURL, "/")
+}
+
+// Lists the builds of all BuildConfigs of the specified StarterKit.
+func (r *StarterKitReconciler) listBuilds(ctx context.Context, instance *devxv1alpha1.StarterKit) ([]buildv1.Build, error) {
+	builds := &buildv1.BuildList{}
+	if err := r.Client.List(ctx, builds, client.InNamespace(instance.Namespace), client.MatchingLabels{"app": instance.Name}); err != nil {
+		return nil, err
+	}
+	return builds.Items, nil
+}
+
+// Posts the results of the builds and of the latest rollout of the specified StarterKit as commit statuses on the
+// built commits. The state last reported is recorded on each build and on the DeploymentConfig, so every state
+// change is reported exactly once.
+func (r *StarterKitReconciler) reconcileCommitStatuses(ctx context.Context, githubClient *github.Client, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	if !instance.Spec.Reporting.CommitStatuses {
+		return nil
+	}
+	owner := instance.Spec.TemplateRepo.Owner
+	repo := instance.Spec.TemplateRepo.Name
+
+	builds, err := r.listBuilds(ctx, instance)
+	if err != nil {
+		reqLogger.Error(err, "Error listing Builds")
+		return err
+	}
+	consoleURL := r.consoleURL(ctx)
+	for i := range builds {
+		build := &builds[i]
+		commit := buildCommit(build)
+		state := buildCommitState(build.Status.Phase)
+		if commit == "" || build.Annotations[reportedStatusAnnotation] == state {
+			continue
+		}
+
+		status := &github.RepoStatus{
+			State:       github.String(state),
+			Description: github.String(fmt.Sprintf("Build %s %s", build.Name, strings.ToLower(string(build.Status.Phase)))),
+			Context:     github.String(buildStatusContext),
+		}
+		if consoleURL != "" {
+			status.TargetURL = github.String(fmt.Sprintf("%s/k8s/ns/%s/builds/%s/logs", consoleURL, build.Namespace, build.Name))
+		}
+		if _, _, err := githubClient.Repositories.CreateStatus(ctx, owner, repo, commit, status); err != nil {
+			reqLogger.Error(err, "Error posting build commit status", "Build.Name", build.Name)
+			return err
+		}
+		if err := r.annotateReportedStatus(ctx, build, state); err != nil {
+			reqLogger.Error(err, "Error recording reported build status", "Build.Name", build.Name)
+			return err
+		}
+	}
+
+	if instance.Spec.Runtime == devxv1alpha1.RuntimeKnative {
+		return nil
+	}
+	dc := &appsv1.DeploymentConfig{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, dc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		reqLogger.Error(err, "Error fetching Deployment")
+		return err
+	}
+	state := rolloutCommitState(dc)
+	commit := rolloutCommit(dc, builds)
+	reported := fmt.Sprintf("%d/%s", dc.Status.LatestVersion, state)
+	if state == "" || commit == "" || dc.Annotations[reportedStatusAnnotation] == reported {
+		return nil
+	}
+
+	status := &github.RepoStatus{
+		State:       github.String(state),
+		Description: github.String(fmt.Sprintf("Rollout %s-%d %s", dc.Name, dc.Status.LatestVersion, rolloutDescription(state))),
+		Context:     github.String(deployStatusContext),
+	}
+	if instance.Status.URL != "" {
+		status.TargetURL = github.String(instance.Status.URL)
+	}
+	if _, _, err := githubClient.Repositories.CreateStatus(ctx, owner, repo, commit, status); err != nil {
+		reqLogger.Error(err, "Error posting rollout commit status")
+		return err
+	}
+	if err := r.annotateReportedStatus(ctx, dc, reported); err != nil {
+		reqLogger.Error(err, "Error recording reported rollout status")
+		return err
+	}
+	return nil
+}
+
+// Records the latest rollout of the specified StarterKit as a GitHub deployment of its environment once it succeeded.
+// The deployment is created without merging or status checks, since it records a rollout that already happened, and
+// supersedes the earlier deployments of the environment. Only rollouts of the DeploymentConfig are recorded, like
+// for the rollout commit statuses.
+func (r *StarterKitReconciler) reconcileGitHubDeployments(ctx context.Context, githubClient *github.Client, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	if !instance.Spec.Reporting.Deployments || instance.Spec.Runtime == devxv1alpha1.RuntimeKnative {
+		return nil
+	}
+
+	dc := &appsv1.DeploymentConfig{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, dc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		reqLogger.Error(err, "Error fetching Deployment")
+		return err
+	}
+	version := strconv.FormatInt(dc.Status.LatestVersion, 10)
+	if rolloutCommitState(dc) != commitStateSuccess || dc.Annotations[reportedDeploymentAnnotation] == version {
+		return nil
+	}
+	builds, err := r.listBuilds(ctx, instance)
+	if err != nil {
+		reqLogger.Error(err, "Error listing Builds")
+		return err
+	}
+	commit := rolloutCommit(dc, builds)
+	if commit == "" {
+		return nil
+	}
+
+	environment := instance.Spec.Reporting.Environment
+	if environment == "" {
+		environment = instance.Namespace
+	}
+	owner := instance.Spec.TemplateRepo.Owner
+	repo := instance.Spec.TemplateRepo.Name
+	description := fmt.Sprintf("Rollout %s-%s", dc.Name, version)
+	deployment, _, err := githubClient.Repositories.CreateDeployment(ctx, owner, repo, &github.DeploymentRequest{
+		Ref:              github.String(commit),
+		AutoMerge:        github.Bool(false),
+		RequiredContexts: &[]string{},
+		Environment:      github.String(environment),
+		Description:      github.String(description),
+	})
+	if err != nil {
+		reqLogger.Error(err, "Error creating GitHub deployment")
+		return err
+	}
+	status := &github.DeploymentStatusRequest{
+		State:        github.String(commitStateSuccess),
+		Description:  github.String(description + " available"),
+		Environment:  github.String(environment),
+		AutoInactive: github.Bool(true),
+	}
+	if instance.Status.URL != "" {
+		status.EnvironmentURL = github.String(instance.Status.URL)
+	}
+	if consoleURL := r.consoleURL(ctx); consoleURL != "" {
+		status.LogURL = github.String(fmt.Sprintf("%s/k8s/ns/%s/deploymentconfigs/%s", consoleURL, dc.Namespace, dc.Name))
+	}
+	if _, _, err := githubClient.Repositories.CreateDeploymentStatus(ctx, owner, repo, deployment.GetID(), status); err != nil {
+		reqLogger.Error(err, "Error creating GitHub deployment status")
+		return err
+	}
+	reqLogger.Info("GitHub deployment created successfully", "environment", environment, "Deployment.ID", deployment.GetID())
+
+	patch := client.MergeFrom(dc.DeepCopy())
+	if dc.Annotations == nil {
+		dc.Annotations = map[string]string{}
+	}
+	dc.Annotations[reportedDeploymentAnnotation] = version
+	if err := r.Client.Patch(ctx, dc, patch); err != nil {
+		reqLogger.Error(err, "Error recording reported rollout")
+		return err
+	}
+	instance.Status.GitHubDeploymentID = deployment.GetID()
+	return r.updateStarterKitStatus(ctx, instance)
+}
+
+// Records the result of reporting to GitHub in the condition of the specified type, and requeues the request to retry
+// a failed report. A failed report does not fail the reconcile, so that an unavailable GitHub API cannot hold up the
+// StarterKit. The condition is removed when the reporting is disabled.
+func (r *StarterKitReconciler) setReportedCondition(ctx context.Context, instance *devxv1alpha1.StarterKit, conditionType string, enabled bool, reportErr error, result *ctrl.Result) error {
+	status := instance.Status.DeepCopy()
+	switch {
+	case !enabled:
+		meta.RemoveStatusCondition(&status.Conditions, conditionType)
+	case reportErr != nil:
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             metav1.ConditionFalse,
+			Reason:             "Failed",
+			Message:            reportErr.Error(),
+			ObservedGeneration: instance.Generation,
+		})
+		requeueAfter(result, reportingRetryDelay)
+	default:
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Reported",
+			Message:            "Reported to GitHub",
+			ObservedGeneration: instance.Generation,
+		})
+	}
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.updateStarterKitStatus(ctx, instance)
+}
+
+// Returns the description of a rollout in the specified commit status state.
+func rolloutDescription(state string) string {
+	switch state {
+	case commitStateSuccess:
+		return "available"
+	case commitStateFailure:
+		return "failed"
+	default:
+		return "in progress"
+	}
+}
+
+// Records the state last reported to GitHub on the specified object.
+func (r *StarterKitReconciler) annotateReportedStatus(ctx context.Context, obj client.Object, state string) error {
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[reportedStatusAnnotation] = state
+	obj.SetAnnotations(annotations)
+	return r.Client.Patch(ctx, obj, patch)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// serviceBindingRoot is the directory the binding secrets are projected into
+const serviceBindingRoot = "/bindings"
+
+// serviceBindingRootEnv is the environment variable telling the application where to find the binding secrets
+const serviceBindingRootEnv = "SERVICE_BINDING_ROOT"
+
+// serviceBindingVolumePrefix is the prefix of the names of the volumes projecting binding secrets
+const serviceBindingVolumePrefix = "service-binding-"
+
+// serviceBindingResyncInterval is how often the binding secret names of Provisioned Services are checked. Provisioned
+// Services can be of any kind, so they are not watched.
+const serviceBindingResyncInterval = time.Minute
+
+// Resolves the binding secrets of the service bindings of the specified StarterKit, records them in its status and
+// reports whether all of them are available.
+func (r *StarterKitReconciler) reconcileServiceBindings(ctx context.Context, instance *devxv1alpha1.StarterKit, result *ctrl.Result, reqLogger logr.Logger) (bool, error) {
+	var serviceBindings []devxv1alpha1.StarterKitStatusServiceBinding
+	var pending []string
+	for _, sb := range instance.Spec.ServiceBindings {
+		status := devxv1alpha1.StarterKitStatusServiceBinding{Name: sb.Name, SecretName: sb.SecretName}
+		if sb.Service != nil {
+			requeueAfter(result, serviceBindingResyncInterval)
+			secretName, reason, err := r.provisionedServiceSecretName(ctx, instance.Namespace, sb.Service)
+			if err != nil {
+				reqLogger.Error(err, "Error fetching Provisioned Service", "Service.Kind", sb.Service.Kind, "Service.Name", sb.Service.Name)
+				return false, err
+			}
+			if reason != "" {
+				pending = append(pending, fmt.Sprintf("%s (%s %s %s)", sb.Name, sb.Service.Kind, sb.Service.Name, reason))
+				serviceBindings = append(serviceBindings, status)
+				continue
+			}
+			status.SecretName = secretName
+		}
+		if status.SecretName == "" {
+			pending = append(pending, fmt.Sprintf("%s (no binding secret)", sb.Name))
+		} else {
+			secret := &corev1.Secret{}
+			err := r.Client.Get(ctx, types.NamespacedName{Name: status.SecretName, Namespace: instance.Namespace}, secret)
+			if err != nil && errors.IsNotFound(err) {
+				pending = append(pending, fmt.Sprintf("%s (secret %s not found)", sb.Name, status.SecretName))
+			} else if err != nil {
+				reqLogger.Error(err, "Error fetching binding secret", "Secret.Name", status.SecretName)
+				return false, err
+			}
+		}
+		serviceBindings = append(serviceBindings, status)
+	}
+
+	status := instance.Status.DeepCopy()
+	status.ServiceBindings = serviceBindings
+	if len(pending) > 0 {
+		reqLogger.Info("Waiting for binding secrets", "ServiceBindings", pending)
+	}
+	err := r.updateReadyCondition(ctx, instance, status, readyCondition{
+		Type:           devxv1alpha1.ConditionServiceBindingsReady,
+		Reason:         "Available",
+		Message:        "All binding secrets are available",
+		WaitingReason:  "Waiting",
+		WaitingMessage: "Waiting for service bindings",
+	}, len(serviceBindings) == 0, pending)
+	return len(pending) == 0, err
+}
+
+// Returns the name of the binding secret of the specified Provisioned Service, or an empty string if it has not
+// published a binding secret yet. When the Provisioned Service cannot be read, because it does not exist or the
+// operator is not allowed to read its kind, the reason is returned instead.
+func (r *StarterKitReconciler) provisionedServiceSecretName(ctx context.Context, namespace string, ref *devxv1alpha1.StarterKitSpecServiceReference) (string, string, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return "", "", err
+	}
+	service := &unstructured.Unstructured{}
+	service.SetGroupVersionKind(gv.WithKind(ref.Kind))
+	err = r.Client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, service)
+	if err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return "", "not found", nil
+		}
+		if errors.IsForbidden(err) {
+			return "", "forbidden", nil
+		}
+		return "", "", err
+	}
+	secretName, _, err := unstructured.NestedString(service.Object, "status", "binding", "name")
+	return secretName, "", err
+}
+
+// Projects the binding secrets of the service bindings of the specified StarterKit into the given container of the
+// pod spec and points SERVICE_BINDING_ROOT at them, and removes the binding secrets that are no longer listed.
+func setServiceBindings(spec *corev1.PodSpec, container string, cr *devxv1alpha1.StarterKit) {
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for _, v := range spec.Volumes {
+		if !strings.HasPrefix(v.Name, serviceBindingVolumePrefix) {
+			volumes = append(volumes, v)
+		}
+	}
+	mode := corev1.SecretVolumeSourceDefaultMode
+	for _, sb := range cr.Status.ServiceBindings {
+		if sb.SecretName == "" {
+			continue
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: serviceBindingVolumePrefix + sb.Name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: sb.SecretName, DefaultMode: &mode},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: serviceBindingVolumePrefix + sb.Name, MountPath: path.Join(serviceBindingRoot, sb.Name), ReadOnly: true})
+	}
+	spec.Volumes = volumes
+
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		if c.Name != container {
+			continue
+		}
+		var containerMounts []corev1.VolumeMount
+		for _, m := range c.VolumeMounts {
+			if !strings.HasPrefix(m.Name, serviceBindingVolumePrefix) {
+				containerMounts = append(containerMounts, m)
+			}
+		}
+		c.VolumeMounts = append(containerMounts, mounts...)
+
+		// Copy the environment variables, as they may be shared with the StarterKit spec
+		env := append([]corev1.EnvVar{}, c.Env...)
+		if len(mounts) > 0 {
+			env = setEnvVar(env, corev1.EnvVar{Name: serviceBindingRootEnv, Value: serviceBindingRoot})
+		} else if !hasEnvVar(cr.Spec.Options.Env, serviceBindingRootEnv) {
+			env = removeEnvVar(env, serviceBindingRootEnv)
+		}
+		c.Env = env
+	}
+}
+
+// Returns true if the specified environment variable is set.
+func hasEnvVar(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Removes the specified environment variable.
+func removeEnvVar(env []corev1.EnvVar, name string) []corev1.EnvVar {
+	var out []corev1.EnvVar
+	for _, e := range env {
+		if e.Name != name {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Injects the credentials of the IBM Cloud Operator Bindings and the binding secrets of the service bindings of the
+// specified StarterKit into the given container of the pod spec. Returns true if the pod spec was changed.
+func injectBindings(spec *corev1.PodSpec, container string, cr *devxv1alpha1.StarterKit) bool {
+	original := spec.DeepCopy()
+	setBindings(spec, container, cr)
+	setServiceBindings(spec, container, cr)
+	return !equality.Semantic.DeepEqual(original, spec)
+}
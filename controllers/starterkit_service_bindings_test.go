@@ -0,0 +1,85 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// failingGetClient fails every Get with the specified error.
+type failingGetClient struct {
+	client.Client
+	err error
+}
+
+func (c *failingGetClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return c.err
+}
+
+func TestProvisionedServiceSecretName(t *testing.T) {
+	newService := func(name string, secretName string) *unstructured.Unstructured {
+		service := &unstructured.Unstructured{}
+		service.SetAPIVersion("example.com/v1")
+		service.SetKind("Database")
+		service.SetName(name)
+		service.SetNamespace("dev")
+		if secretName != "" {
+			_ = unstructured.SetNestedField(service.Object, secretName, "status", "binding", "name")
+		}
+		return service
+	}
+	resource := schema.GroupResource{Group: "example.com", Resource: "databases"}
+
+	tests := []struct {
+		name       string
+		ref        devxv1alpha1.StarterKitSpecServiceReference
+		getErr     error
+		wantSecret string
+		wantReason string
+		wantErr    bool
+	}{
+		{name: "binding secret published", ref: devxv1alpha1.StarterKitSpecServiceReference{APIVersion: "example.com/v1", Kind: "Database", Name: "published"}, wantSecret: "db-binding"},
+		{name: "binding secret not published yet", ref: devxv1alpha1.StarterKitSpecServiceReference{APIVersion: "example.com/v1", Kind: "Database", Name: "provisioning"}},
+		{name: "not found", ref: devxv1alpha1.StarterKitSpecServiceReference{APIVersion: "example.com/v1", Kind: "Database", Name: "missing"}, wantReason: "not found"},
+		{name: "forbidden", ref: devxv1alpha1.StarterKitSpecServiceReference{APIVersion: "example.com/v1", Kind: "Database", Name: "published"}, getErr: errors.NewForbidden(resource, "published", nil), wantReason: "forbidden"},
+		{name: "server error", ref: devxv1alpha1.StarterKitSpecServiceReference{APIVersion: "example.com/v1", Kind: "Database", Name: "published"}, getErr: errors.NewServiceUnavailable("unavailable"), wantErr: true},
+		{name: "invalid API version", ref: devxv1alpha1.StarterKitSpecServiceReference{APIVersion: "example.com/v1/beta", Kind: "Database", Name: "published"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestServicesReconciler(t, newService("published", "db-binding"), newService("provisioning", ""))
+			if tt.getErr != nil {
+				r.Client = &failingGetClient{Client: r.Client, err: tt.getErr}
+			}
+
+			secretName, reason, err := r.provisionedServiceSecretName(context.Background(), "dev", &tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("provisionedServiceSecretName() error = %v, want error %v", err, tt.wantErr)
+			}
+			if secretName != tt.wantSecret || reason != tt.wantReason {
+				t.Errorf("provisionedServiceSecretName() = %q, %q, want %q, %q", secretName, reason, tt.wantSecret, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ibmCloudServiceGVK is the IBM Cloud Operator Service kind
+var ibmCloudServiceGVK = schema.GroupVersionKind{Group: "ibmcloud.ibm.com", Version: "v1alpha1", Kind: "Service"}
+
+// serviceStateOnline is the state of an IBM Cloud Operator Service that has been provisioned
+const serviceStateOnline = "Online"
+
+// Returns the labels of the IBM Cloud Operator objects created for the specified StarterKit.
+func ibmCloudLabelsForCR(cr *devxv1alpha1.StarterKit) map[string]string {
+	return map[string]string{
+		"app":  cr.Name,
+		"devx": "",
+	}
+}
+
+// Create a new IBM Cloud Operator Service
+func newIBMCloudServiceForCR(cr *devxv1alpha1.StarterKit, svc *devxv1alpha1.StarterKitSpecService) *unstructured.Unstructured {
+	service := &unstructured.Unstructured{}
+	service.SetGroupVersionKind(ibmCloudServiceGVK)
+	service.SetName(svc.Name)
+	service.SetNamespace(cr.Namespace)
+	service.SetLabels(ibmCloudLabelsForCR(cr))
+	service.Object["spec"] = map[string]interface{}{
+		"serviceClass": svc.ServiceClass,
+		"plan":         svc.Plan,
+	}
+	return service
+}
+
+// Create a new IBM Cloud Operator Binding for the Service of the same name
+func newIBMCloudBindingForCR(cr *devxv1alpha1.StarterKit, svc *devxv1alpha1.StarterKitSpecService) *unstructured.Unstructured {
+	binding := &unstructured.Unstructured{}
+	binding.SetGroupVersionKind(bindingGVK)
+	binding.SetName(svc.Name)
+	binding.SetNamespace(cr.Namespace)
+	binding.SetLabels(ibmCloudLabelsForCR(cr))
+	binding.Object["spec"] = map[string]interface{}{
+		"serviceName": svc.Name,
+	}
+	return binding
+}
+
+// Creates the IBM Cloud Operator Services and Bindings of the specified StarterKit, deletes the ones of services that
+// are no longer listed unless they are retained, records the state of the Services in its status and reports
+// whether all of them are Online.
+func (r *StarterKitReconciler) reconcileServices(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) (bool, error) {
+	var services []devxv1alpha1.StarterKitStatusService
+	var pending []string
+	keep := map[string]bool{}
+	for i := range instance.Spec.Services {
+		svc := &instance.Spec.Services[i]
+		keep[svc.Name] = true
+		service, err := r.reconcileIBMCloudObject(ctx, instance, svc, newIBMCloudServiceForCR(instance, svc), reqLogger)
+		if err != nil {
+			return false, err
+		}
+		if _, err := r.reconcileIBMCloudObject(ctx, instance, svc, newIBMCloudBindingForCR(instance, svc), reqLogger); err != nil {
+			return false, err
+		}
+		state, _, _ := unstructured.NestedString(service.Object, "status", "state")
+		if state != serviceStateOnline {
+			pending = append(pending, fmt.Sprintf("%s (%s)", svc.Name, state))
+		}
+		services = append(services, devxv1alpha1.StarterKitStatusService{Name: svc.Name, State: state})
+	}
+	if err := r.deleteServices(ctx, instance, keep, reqLogger); err != nil {
+		return false, err
+	}
+
+	status := instance.Status.DeepCopy()
+	status.Services = services
+	if len(pending) > 0 {
+		reqLogger.Info("Waiting for services to be Online", "Services", pending)
+	}
+	err := r.updateReadyCondition(ctx, instance, status, readyCondition{
+		Type:           devxv1alpha1.ConditionServicesReady,
+		Reason:         "Online",
+		Message:        "All services are Online",
+		WaitingReason:  "Provisioning",
+		WaitingMessage: "Waiting for services",
+	}, len(services) == 0, pending)
+	return len(pending) == 0, err
+}
+
+// Creates the specified IBM Cloud Operator object if it does not exist yet and returns the object found in the
+// cluster. The StarterKit controls the object unless the service is retained, so that it is garbage collected with
+// the StarterKit. Objects that were not created for the StarterKit are used as is.
+func (r *StarterKitReconciler) reconcileIBMCloudObject(ctx context.Context, instance *devxv1alpha1.StarterKit, svc *devxv1alpha1.StarterKitSpecService, obj *unstructured.Unstructured, reqLogger logr.Logger) (*unstructured.Unstructured, error) {
+	kind := obj.GetKind()
+	retain := svc.DeletionPolicy == devxv1alpha1.DeletionPolicyRetain
+	if !retain {
+		// Set StarterKit instance as the owner and controller
+		if err := controllerutil.SetControllerReference(instance, obj, r.Scheme); err != nil {
+			reqLogger.Error(err, "Error setting "+kind+" on StarterKit")
+			return nil, err
+		}
+	}
+
+	// Check if this object already exists
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(obj.GroupVersionKind())
+	err := r.Client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
+	if err != nil && errors.IsNotFound(err) {
+		reqLogger.Info("Creating a new "+kind, kind+".Namespace", obj.GetNamespace(), kind+".Name", obj.GetName())
+		if err := r.Client.Create(ctx, obj); err != nil {
+			reqLogger.Error(err, "Error creating "+kind)
+			return nil, err
+		}
+		reqLogger.Info(kind + " created successfully")
+		return obj, nil
+	} else if err != nil {
+		reqLogger.Error(err, "Error fetching "+kind)
+		return nil, err
+	}
+
+	// Follow changes of the deletion policy
+	if found.GetLabels()["app"] != instance.Name {
+		reqLogger.Info("Skip reconcile: "+kind+" was not created for the StarterKit", kind+".Namespace", found.GetNamespace(), kind+".Name", found.GetName())
+		return found, nil
+	}
+	controlled := metav1.IsControlledBy(found, instance)
+	if retain && controlled {
+		var refs []metav1.OwnerReference
+		for _, ref := range found.GetOwnerReferences() {
+			if ref.UID != instance.UID {
+				refs = append(refs, ref)
+			}
+		}
+		found.SetOwnerReferences(refs)
+	} else if !retain && !controlled {
+		if err := controllerutil.SetControllerReference(instance, found, r.Scheme); err != nil {
+			reqLogger.Error(err, "Error setting "+kind+" on StarterKit")
+			return nil, err
+		}
+	} else {
+		reqLogger.Info("Skip reconcile: "+kind+" already exists", kind+".Namespace", found.GetNamespace(), kind+".Name", found.GetName())
+		return found, nil
+	}
+	reqLogger.Info("Updating "+kind+" deletion policy", kind+".Namespace", found.GetNamespace(), kind+".Name", found.GetName(), "retain", retain)
+	if err := r.Client.Update(ctx, found); err != nil {
+		reqLogger.Error(err, "Error updating "+kind)
+		return nil, err
+	}
+	return found, nil
+}
+
+// Deletes the IBM Cloud Operator Bindings and Services controlled by the specified StarterKit whose service is no
+// longer listed. Retained services are not controlled by the StarterKit and are left alone.
+func (r *StarterKitReconciler) deleteServices(ctx context.Context, instance *devxv1alpha1.StarterKit, keep map[string]bool, reqLogger logr.Logger) error {
+	for _, gvk := range []schema.GroupVersionKind{bindingGVK, ibmCloudServiceGVK} {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err := r.Client.List(ctx, list, client.InNamespace(instance.Namespace), client.MatchingLabels(ibmCloudLabelsForCR(instance)))
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				// The IBM Cloud Operator is not installed
+				return nil
+			}
+			reqLogger.Error(err, "Error listing "+gvk.Kind+"s")
+			return err
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if keep[obj.GetName()] || !metav1.IsControlledBy(obj, instance) {
+				continue
+			}
+			reqLogger.Info("Deleting "+gvk.Kind, gvk.Kind+".Namespace", obj.GetNamespace(), gvk.Kind+".Name", obj.GetName())
+			if err := r.Client.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+				reqLogger.Error(err, "Error deleting "+gvk.Kind)
+				return err
+			}
+		}
+	}
+	return nil
+}
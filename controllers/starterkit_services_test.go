@@ -0,0 +1,148 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Returns a StarterKit reconciler backed by a fake client holding the specified objects.
+func newTestServicesReconciler(t *testing.T, objs ...client.Object) *StarterKitReconciler {
+	scheme := runtime.NewScheme()
+	if err := devxv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return &StarterKitReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		Log:    log.Log,
+		Scheme: scheme,
+	}
+}
+
+// Returns a StarterKit providing the specified IBM Cloud service.
+func newTestServicesStarterKit(svc devxv1alpha1.StarterKitSpecService) *devxv1alpha1.StarterKit {
+	return &devxv1alpha1.StarterKit{
+		TypeMeta:   metav1.TypeMeta{APIVersion: devxv1alpha1.GroupVersion.String(), Kind: "StarterKit"},
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "dev", UID: "uid"},
+		Spec:       devxv1alpha1.StarterKitSpec{Services: []devxv1alpha1.StarterKitSpecService{svc}},
+	}
+}
+
+func TestReconcileIBMCloudObject(t *testing.T) {
+	svc := devxv1alpha1.StarterKitSpecService{Name: "db", ServiceClass: "cloudantnosqldb", Plan: "lite"}
+	retained := svc
+	retained.DeletionPolicy = devxv1alpha1.DeletionPolicyRetain
+
+	tests := []struct {
+		name           string
+		svc            devxv1alpha1.StarterKitSpecService
+		existing       func(cr *devxv1alpha1.StarterKit) *unstructured.Unstructured
+		wantControlled bool
+	}{
+		{name: "created", svc: svc, wantControlled: true},
+		{name: "created retained", svc: retained},
+		{
+			name: "switched to retained",
+			svc:  retained,
+			existing: func(cr *devxv1alpha1.StarterKit) *unstructured.Unstructured {
+				service := newIBMCloudServiceForCR(cr, &svc)
+				service.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(cr, devxv1alpha1.GroupVersion.WithKind("StarterKit"))})
+				return service
+			},
+		},
+		{
+			name: "switched to deleted",
+			svc:  svc,
+			existing: func(cr *devxv1alpha1.StarterKit) *unstructured.Unstructured {
+				return newIBMCloudServiceForCR(cr, &svc)
+			},
+			wantControlled: true,
+		},
+		{
+			name: "not created for the StarterKit",
+			svc:  svc,
+			existing: func(cr *devxv1alpha1.StarterKit) *unstructured.Unstructured {
+				service := newIBMCloudServiceForCR(cr, &svc)
+				service.SetLabels(nil)
+				return service
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := newTestServicesStarterKit(tt.svc)
+			var objs []client.Object
+			if tt.existing != nil {
+				objs = append(objs, tt.existing(cr))
+			}
+			r := newTestServicesReconciler(t, objs...)
+
+			if _, err := r.reconcileIBMCloudObject(context.Background(), cr, &tt.svc, newIBMCloudServiceForCR(cr, &tt.svc), log.Log); err != nil {
+				t.Fatal(err)
+			}
+			found := emptyIBMCloudObject(ibmCloudServiceGVK.Kind)
+			if err := r.Client.Get(context.Background(), types.NamespacedName{Name: "db", Namespace: "dev"}, found); err != nil {
+				t.Fatal(err)
+			}
+			if controlled := metav1.IsControlledBy(found, cr); controlled != tt.wantControlled {
+				t.Errorf("controlled = %v, want %v", controlled, tt.wantControlled)
+			}
+		})
+	}
+}
+
+func TestDeleteServices(t *testing.T) {
+	cr := newTestServicesStarterKit(devxv1alpha1.StarterKitSpecService{Name: "db"})
+	controlled := func(name string) *unstructured.Unstructured {
+		service := newIBMCloudServiceForCR(cr, &devxv1alpha1.StarterKitSpecService{Name: name})
+		service.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(cr, devxv1alpha1.GroupVersion.WithKind("StarterKit"))})
+		return service
+	}
+	r := newTestServicesReconciler(t,
+		controlled("db"),
+		controlled("removed"),
+		newIBMCloudServiceForCR(cr, &devxv1alpha1.StarterKitSpecService{Name: "retained"}),
+	)
+
+	if err := r.deleteServices(context.Background(), cr, map[string]bool{"db": true}, log.Log); err != nil {
+		t.Fatal(err)
+	}
+	for name, wantDeleted := range map[string]bool{"db": false, "removed": true, "retained": false} {
+		err := r.Client.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "dev"}, emptyIBMCloudObject(ibmCloudServiceGVK.Kind))
+		if deleted := errors.IsNotFound(err); deleted != wantDeleted {
+			t.Errorf("Service %s deleted = %v, want %v (%v)", name, deleted, wantDeleted, err)
+		}
+	}
+}
+
+// Returns an empty IBM Cloud Operator object of the specified kind to fetch objects into.
+func emptyIBMCloudObject(kind string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ibmCloudServiceGVK.GroupVersion().WithKind(kind))
+	return obj
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Collects the observed state of the resources owned by the specified StarterKit and updates its status if it
+// changed. Resources that do not exist yet are skipped.
+func (r *StarterKitReconciler) updateStatus(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	status := instance.Status.DeepCopy()
+	name := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+
+	// Application URL
+	if instance.Spec.Runtime == devxv1alpha1.RuntimeKnative {
+		url, err := r.knativeServiceURL(ctx, instance)
+		if err != nil {
+			return err
+		}
+		status.URL = url
+	} else if instance.Spec.Exposure.GatewayAPI != nil {
+		url, err := r.httpRouteURL(ctx, instance)
+		if err != nil {
+			return err
+		}
+		status.URL = url
+	} else {
+		route := &routev1.Route{}
+		if err := r.Client.Get(ctx, name, route); err == nil {
+			status.URL = urlForRoute(route)
+		} else if !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	// Latest Build
+	buildConfig := &buildv1.BuildConfig{}
+	if err := r.Client.Get(ctx, name, buildConfig); err == nil && buildConfig.Status.LastVersion > 0 {
+		build := &buildv1.Build{}
+		buildName := types.NamespacedName{Name: fmt.Sprintf("%s-%d", buildConfig.Name, buildConfig.Status.LastVersion), Namespace: instance.Namespace}
+		if err := r.Client.Get(ctx, buildName, build); err == nil {
+			status.LatestBuild = &devxv1alpha1.StarterKitStatusBuild{
+				Name:                build.Name,
+				Phase:               string(build.Status.Phase),
+				StartTimestamp:      build.Status.StartTimestamp,
+				CompletionTimestamp: build.Status.CompletionTimestamp,
+			}
+		} else if !errors.IsNotFound(err) {
+			return err
+		}
+	} else if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	// Image digest and history
+	image := &imagev1.ImageStream{}
+	if err := r.Client.Get(ctx, name, image); err == nil {
+		status.ImageDigest = latestImageDigest(image, "latest")
+		builds, err := r.listBuilds(ctx, instance)
+		if err != nil {
+			return err
+		}
+		status.ImageHistory = imageHistory(image, "latest", builds)
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	// Deployment
+	deployment := &appsv1.DeploymentConfig{}
+	if err := r.Client.Get(ctx, name, deployment); err == nil {
+		status.LatestRevision = deployment.Status.LatestVersion
+		status.AvailableReplicas = deployment.Status.AvailableReplicas
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	reqLogger.Info("Updating StarterKit status")
+	instance.Status = *status
+	return r.updateStarterKitStatus(ctx, instance)
+}
+
+// Updates the status of the specified StarterKit. The spec of the StarterKit is kept as is, as it holds the
+// defaults merged from its template and manifest, which the response of the update would discard.
+func (r *StarterKitReconciler) updateStarterKitStatus(ctx context.Context, instance *devxv1alpha1.StarterKit) error {
+	spec := instance.Spec.DeepCopy()
+	err := r.Client.Status().Update(ctx, instance)
+	instance.Spec = *spec
+	return err
+}
+
+// readyCondition describes a condition reporting whether a kind of resource of a StarterKit is ready
+type readyCondition struct {
+	// Type is the type of the condition
+	Type string
+	// Reason and Message describe the condition while no resource is pending
+	Reason  string
+	Message string
+	// WaitingReason describes the condition while resources are pending, and WaitingMessage prefixes the list of the
+	// pending resources
+	WaitingReason  string
+	WaitingMessage string
+}
+
+// Sets the specified condition on the given copy of the status of the StarterKit, or removes it when the StarterKit
+// has none of the resources it reports on, and updates the status if it changed. The condition is false while any
+// resource is pending.
+func (r *StarterKitReconciler) updateReadyCondition(ctx context.Context, instance *devxv1alpha1.StarterKit, status *devxv1alpha1.StarterKitStatus, ready readyCondition, empty bool, pending []string) error {
+	if empty {
+		meta.RemoveStatusCondition(&status.Conditions, ready.Type)
+	} else {
+		condition := metav1.Condition{
+			Type:               ready.Type,
+			Status:             metav1.ConditionTrue,
+			Reason:             ready.Reason,
+			Message:            ready.Message,
+			ObservedGeneration: instance.Generation,
+		}
+		if len(pending) > 0 {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = ready.WaitingReason
+			condition.Message = ready.WaitingMessage + ": " + strings.Join(pending, ", ")
+		}
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.updateStarterKitStatus(ctx, instance)
+}
+
+// maxImageHistory is the number of images listed in the image history of a StarterKit
+const maxImageHistory = 10
+
+// Returns the digest of the newest image pushed to the specified ImageStream tag, or an empty string if no image
+// has been pushed to it yet.
+func latestImageDigest(image *imagev1.ImageStream, tag string) string {
+	for _, t := range image.Status.Tags {
+		if t.Tag == tag && len(t.Items) > 0 {
+			return t.Items[0].Image
+		}
+	}
+	return ""
+}
+
+// Returns the most recent images pushed to the specified ImageStream tag, newest first, together with the commits
+// the given builds made them from.
+func imageHistory(image *imagev1.ImageStream, tag string, builds []buildv1.Build) []devxv1alpha1.StarterKitStatusImage {
+	var history []devxv1alpha1.StarterKitStatusImage
+	for _, t := range image.Status.Tags {
+		if t.Tag != tag {
+			continue
+		}
+		for _, item := range t.Items {
+			if len(history) == maxImageHistory {
+				break
+			}
+			entry := devxv1alpha1.StarterKitStatusImage{
+				Digest:  item.Image,
+				Created: item.Created,
+			}
+			for i := range builds {
+				if to := builds[i].Status.Output.To; to != nil && to.ImageDigest == item.Image {
+					entry.Commit = buildCommit(&builds[i])
+					break
+				}
+			}
+			history = append(history, entry)
+		}
+	}
+	return history
+}
+
+// Maps a Build to the StarterKit owning its BuildConfig. Builds inherit the labels of their BuildConfig, so the
+// StarterKit is identified by the labels set in newBuildForCR.
+func (r *StarterKitReconciler) starterKitForBuild(obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if _, ok := labels["devx"]; !ok || labels["app"] == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: labels["app"], Namespace: obj.GetNamespace()}}}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	buildv1 "github.com/openshift/api/build/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Merges the defaults of the StarterKitTemplate referenced by the specified StarterKit into its spec. Values set on
+// the StarterKit take precedence. The merged spec is only kept in memory, so later changes of the template apply to
+// the StarterKit as well.
+func (r *StarterKitReconciler) applyTemplate(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	if instance.Spec.TemplateRef == "" {
+		return nil
+	}
+	template := &devxv1alpha1.StarterKitTemplate{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Spec.TemplateRef}, template); err != nil {
+		return err
+	}
+	mergeTemplate(&instance.Spec, &template.Spec)
+	return nil
+}
+
+// Fills the fields of the StarterKit spec that are not set with the defaults of the template.
+func mergeTemplate(spec *devxv1alpha1.StarterKitSpec, template *devxv1alpha1.StarterKitTemplateSpec) {
+	if spec.TemplateRepo.TemplateOwner == "" && spec.TemplateRepo.TemplateRepoName == "" {
+		spec.TemplateRepo.TemplateOwner = template.TemplateOwner
+		spec.TemplateRepo.TemplateRepoName = template.TemplateRepoName
+	}
+	if spec.TemplateRepo.Description == "" {
+		spec.TemplateRepo.Description = template.Description
+	}
+	if spec.Options.Port == 0 && len(spec.Options.Ports) == 0 {
+		spec.Options.Port = template.Port
+	}
+	spec.Options.Env = mergeEnv(spec.Options.Env, template.Env)
+	if spec.Options.BuildStrategy == nil && template.BuildStrategy != nil {
+		spec.Options.BuildStrategy = template.BuildStrategy.DeepCopy()
+	}
+}
+
+// Returns the build strategy of the application image of the specified StarterKit.
+func buildStrategyForCR(cr *devxv1alpha1.StarterKit) buildv1.BuildStrategy {
+	strategy := cr.Spec.Options.BuildStrategy
+	if strategy != nil && strategy.Type == devxv1alpha1.BuildStrategySource && strategy.BuilderImage != nil {
+		return buildv1.BuildStrategy{
+			Type: buildv1.SourceBuildStrategyType,
+			SourceStrategy: &buildv1.SourceBuildStrategy{
+				From: *strategy.BuilderImage,
+				Env:  cr.Spec.Options.Env,
+			},
+		}
+	}
+
+	dockerfilePath := "Dockerfile"
+	if strategy != nil && strategy.DockerfilePath != "" {
+		dockerfilePath = strategy.DockerfilePath
+	}
+	return buildv1.BuildStrategy{
+		Type: buildv1.DockerBuildStrategyType,
+		DockerStrategy: &buildv1.DockerBuildStrategy{
+			DockerfilePath: dockerfilePath,
+		},
+	}
+}
+
+// Maps a StarterKitTemplate to the StarterKits referencing it.
+func (r *StarterKitReconciler) starterKitsForTemplate(obj client.Object) []reconcile.Request {
+	skits := &devxv1alpha1.StarterKitList{}
+	if err := r.Client.List(context.Background(), skits); err != nil {
+		r.Log.Error(err, "Error listing StarterKits for StarterKitTemplate", "StarterKitTemplate.Name", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, skit := range skits.Items {
+		if skit.Spec.TemplateRef == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: skit.Name, Namespace: skit.Namespace}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v39/github"
+	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// imageTagAnnotation is set on tagged builds to the ImageStream tag their image is published as
+const imageTagAnnotation = "devx.ibm.com/image-tag"
+
+// taggedBuildOutputTag is the ImageStream tag tagged builds push their image to before it is published
+const taggedBuildOutputTag = "tagged-build"
+
+// invalidImageTagChars matches the characters git refs may contain but ImageStream tags may not
+var invalidImageTagChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// Returns the name of the BuildConfig building git tags, releases and pull requests of the specified StarterKit.
+func taggedBuildConfigName(cr *devxv1alpha1.StarterKit) string {
+	return cr.Name + "-tagged"
+}
+
+// Returns true if the specified StarterKit builds images that are published under their own ImageStream tag.
+func wantsTaggedBuilds(cr *devxv1alpha1.StarterKit) bool {
+	return len(cr.Spec.Triggers.Tags) > 0 || cr.Spec.Triggers.Releases || cr.Spec.Triggers.PullRequests
+}
+
+// Returns the ImageStream tag the image built from the specified git tag or pull request is published as.
+func imageTagForRef(ref string) string {
+	tag := invalidImageTagChars.ReplaceAllString(ref, "-")
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	return tag
+}
+
+// Returns true if the specified name matches one of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the GitHub events the webhook of the specified StarterKit subscribes to.
+func hookEventsForCR(cr *devxv1alpha1.StarterKit) []string {
+	events := []string{"push"}
+	if cr.Spec.Triggers.Releases {
+		events = append(events, "release")
+	}
+	if cr.Spec.Triggers.PullRequests || cr.Spec.Previews.Enabled {
+		events = append(events, "pull_request")
+	}
+	return events
+}
+
+// Create a new BuildConfig for tagged builds. It is only instantiated by the GitHubWebhookReceiver, so it has no
+// triggers, and pushes to a staging tag from which the image is published under the tag of the build.
+func newTaggedBuildForCR(cr *devxv1alpha1.StarterKit) *buildv1.BuildConfig {
+	build := newBuildForCR(cr)
+	build.Name = taggedBuildConfigName(cr)
+	build.Spec.Output.To.Name = cr.Name + ":" + taggedBuildOutputTag
+	build.Spec.Triggers = nil
+	return build
+}
+
+// Creates the BuildConfig for tagged builds of the specified StarterKit if any trigger needs it, and deletes it
+// otherwise.
+func (r *StarterKitReconciler) reconcileTaggedBuildConfig(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	build := newTaggedBuildForCR(instance)
+	found := &buildv1.BuildConfig{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: build.Name, Namespace: build.Namespace}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "Error fetching tagged Build")
+		return err
+	}
+	exists := err == nil
+
+	if !wantsTaggedBuilds(instance) {
+		if exists && metav1.IsControlledBy(found, instance) {
+			reqLogger.Info("Deleting tagged Build", "Build.Namespace", found.Namespace, "Build.Name", found.Name)
+			if err := r.Client.Delete(ctx, found); err != nil && !errors.IsNotFound(err) {
+				reqLogger.Error(err, "Error deleting tagged Build")
+				return err
+			}
+		}
+		return nil
+	}
+	if exists {
+		reqLogger.Info("Skip reconcile: tagged Build already exists", "Build.Namespace", found.Namespace, "Build.Name", found.Name)
+		return nil
+	}
+
+	if err := controllerutil.SetControllerReference(instance, build, r.Scheme); err != nil {
+		reqLogger.Error(err, "Error setting tagged Build on StarterKit")
+		return err
+	}
+	reqLogger.Info("Creating a new tagged Build", "Build.Namespace", build.Namespace, "Build.Name", build.Name)
+	if err := r.Client.Create(ctx, build); err != nil {
+		reqLogger.Error(err, "Error creating tagged Build")
+		return err
+	}
+	reqLogger.Info("Tagged Build created successfully")
+	return nil
+}
+
+// Publishes the images of completed tagged builds of the specified StarterKit under the ImageStream tag recorded on
+// each build. When several builds were made for the same tag, e.g. because a git tag was moved, the image of the
+// latest one wins.
+func (r *StarterKitReconciler) reconcileImageStreamTags(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	if !wantsTaggedBuilds(instance) {
+		return nil
+	}
+
+	builds := &buildv1.BuildList{}
+	if err := r.Client.List(ctx, builds, client.InNamespace(instance.Namespace), client.MatchingLabels{buildv1.BuildConfigLabel: taggedBuildConfigName(instance)}); err != nil {
+		reqLogger.Error(err, "Error listing tagged Builds")
+		return err
+	}
+	sort.Slice(builds.Items, func(i, j int) bool {
+		return builds.Items[i].CreationTimestamp.Before(&builds.Items[j].CreationTimestamp)
+	})
+	digests := map[string]string{}
+	for _, b := range builds.Items {
+		tag := b.Annotations[imageTagAnnotation]
+		if tag == "" || b.Status.Phase != buildv1.BuildPhaseComplete || b.Status.Output.To == nil || b.Status.Output.To.ImageDigest == "" {
+			continue
+		}
+		digests[tag] = b.Status.Output.To.ImageDigest
+	}
+	if len(digests) == 0 {
+		return nil
+	}
+
+	image := &imagev1.ImageStream{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, image); err != nil {
+		reqLogger.Error(err, "Error fetching ImageStream")
+		return err
+	}
+	changed := false
+	for tag, digest := range digests {
+		if setImageStreamTag(image, tag, digest) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	reqLogger.Info("Publishing tagged images", "ImageStream.Namespace", image.Namespace, "ImageStream.Name", image.Name)
+	if err := r.Client.Update(ctx, image); err != nil {
+		reqLogger.Error(err, "Error updating ImageStream")
+		return err
+	}
+	reqLogger.Info("ImageStream updated successfully")
+	return nil
+}
+
+// Updates the events the webhook on the target GitHub repo of the specified StarterKit subscribes to when the
+// triggers changed.
+func (r *StarterKitReconciler) reconcileGitHubHookEvents(ctx context.Context, githubClient *github.Client, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	if instance.Status.WebhookID == 0 {
+		return nil
+	}
+	events := hookEventsForCR(instance)
+	if strings.Join(events, ",") == strings.Join(instance.Status.WebhookEvents, ",") {
+		return nil
+	}
+
+	reqLogger.Info("Updating GitHub webhook events", "events", events)
+	hook := &github.Hook{Events: events}
+	if _, _, err := githubClient.Repositories.EditHook(ctx, instance.Spec.TemplateRepo.Owner, instance.Spec.TemplateRepo.Name, instance.Status.WebhookID, hook); err != nil {
+		reqLogger.Error(err, "Error updating GitHub webhook events")
+		return err
+	}
+	instance.Status.WebhookEvents = events
+	return r.updateStarterKitStatus(ctx, instance)
+}
+
+// Starts a new build when the rebuild annotation of the specified StarterKit changed, and a new rollout when its
+// redeploy annotation changed. The handled values are recorded in the status before the build or rollout is started,
+// so every request is handled at most once. Whether they could be started is reported in the ManualTriggersStarted
+// condition, so that the user knows to set the annotation to a new value to retry.
+// Knative Services roll out a new revision on their own when the redeploy annotation changes, since it is copied to
+// their revision template.
+func (r *StarterKitReconciler) reconcileManualTriggers(ctx context.Context, instance *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
+	rebuild := instance.GetAnnotations()[devxv1alpha1.RebuildAnnotation]
+	redeploy := instance.GetAnnotations()[devxv1alpha1.RedeployAnnotation]
+	rebuildRequested := rebuild != "" && rebuild != instance.Status.RebuildRequest
+	redeployRequested := redeploy != "" && redeploy != instance.Status.RedeployRequest
+	if !rebuildRequested && !redeployRequested {
+		return nil
+	}
+
+	// Record the requests as handled before acting on them, so that a conflicting status update cannot start a
+	// second build or rollout for the same request
+	if rebuildRequested {
+		instance.Status.RebuildRequest = rebuild
+	}
+	if redeployRequested {
+		instance.Status.RedeployRequest = redeploy
+	}
+	if err := r.updateStarterKitStatus(ctx, instance); err != nil {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:               devxv1alpha1.ConditionManualTriggersStarted,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Started",
+		Message:            "The requested build and rollout were started",
+		ObservedGeneration: instance.Generation,
+	}
+	if rebuildRequested {
+		build, err := instantiateBuild(ctx, r.BuildClient, instance.Namespace, instance.Name, nil, nil, "Rebuild requested with the "+devxv1alpha1.RebuildAnnotation+" annotation")
+		if err != nil {
+			reqLogger.Error(err, "Error starting requested Build")
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "RebuildFailed"
+			condition.Message = "The requested build could not be started, set the " + devxv1alpha1.RebuildAnnotation + " annotation to a new value to retry: " + err.Error()
+		} else {
+			reqLogger.Info("Rebuild started", "Build.Name", build.Name)
+		}
+	}
+
+	if redeployRequested && instance.Spec.Runtime != devxv1alpha1.RuntimeKnative {
+		request := &appsv1.DeploymentRequest{
+			Name:   instance.Name,
+			Latest: true,
+			Force:  true,
+		}
+		if _, err := r.AppsClient.DeploymentConfigs(instance.Namespace).Instantiate(ctx, instance.Name, request, metav1.CreateOptions{}); err != nil {
+			reqLogger.Error(err, "Error starting requested rollout")
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "RedeployFailed"
+			condition.Message = "The requested rollout could not be started, set the " + devxv1alpha1.RedeployAnnotation + " annotation to a new value to retry: " + err.Error()
+		} else {
+			reqLogger.Info("Redeploy started", "Deployment.Name", instance.Name)
+		}
+	}
+
+	// A failed build or rollout is not retried, since its request was already recorded as handled
+	status := instance.Status.DeepCopy()
+	meta.SetStatusCondition(&status.Conditions, condition)
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	instance.Status = *status
+	return r.updateStarterKitStatus(ctx, instance)
+}
+
+// Points the specified tag of the ImageStream at the image with the given digest, adding the tag if needed. Returns
+// true if the ImageStream was changed and needs to be updated.
+func setImageStreamTag(image *imagev1.ImageStream, tag string, digest string) bool {
+	from := &corev1.ObjectReference{
+		Kind: "ImageStreamImage",
+		Name: image.Name + "@" + digest,
+	}
+	for i := range image.Spec.Tags {
+		if image.Spec.Tags[i].Name != tag {
+			continue
+		}
+		if image.Spec.Tags[i].From != nil && *image.Spec.Tags[i].From == *from {
+			return false
+		}
+		image.Spec.Tags[i].From = from
+		return true
+	}
+	image.Spec.Tags = append(image.Spec.Tags, imagev1.TagReference{
+		Name: tag,
+		From: from,
+		ReferencePolicy: imagev1.TagReferencePolicy{
+			Type: imagev1.SourceTagReferencePolicy,
+		},
+	})
+	return true
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     bool
+	}{
+		{name: "v1.2.0", patterns: []string{"v*"}, want: true},
+		{name: "release/1.2", patterns: []string{"main", "release/*"}, want: true},
+		{name: "release/1.2/hotfix", patterns: []string{"release/*"}},
+		{name: "main", patterns: []string{"main"}, want: true},
+		{name: "mainline", patterns: []string{"main"}},
+		{name: "v1", patterns: []string{"v?"}, want: true},
+		{name: "v1", patterns: []string{"[invalid"}},
+		{name: "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" "+strings.Join(tt.patterns, ","), func(t *testing.T) {
+			if got := matchesAny(tt.patterns, tt.name); got != tt.want {
+				t.Errorf("matchesAny(%v, %q) = %v, want %v", tt.patterns, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageTagForRef(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "v1.2.0", want: "v1.2.0"},
+		{ref: "release/1.2", want: "release-1.2"},
+		{ref: strings.Repeat("a", 200), want: strings.Repeat("a", 128)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := imageTagForRef(tt.ref); got != tt.want {
+				t.Errorf("imageTagForRef(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	appsv1 "github.com/openshift/api/apps/v1"
@@ -17,6 +18,7 @@ import (
 	devxv1alpha1 "github.com/ibm/starter-kit-operator/api/v1alpha1"
 	coreappsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -69,6 +71,15 @@ func (r *StarterKitReconciler) getGitHubClient(githubTokenValue *string, reqLogg
 func (r *StarterKitReconciler) createTargetGitHubRepo(client *github.Client, skit *devxv1alpha1.StarterKit, reqLogger logr.Logger) error {
 	ctx := context.Background()
 	if skit.Status.TargetRepo == "" {
+		// Read the manifest of the template repo
+		if skit.Status.Manifest == nil {
+			manifest, err := fetchManifest(ctx, client, skit.Spec.TemplateRepo.TemplateOwner, skit.Spec.TemplateRepo.TemplateRepoName, reqLogger)
+			if err != nil {
+				return err
+			}
+			skit.Status.Manifest = manifest
+		}
+
 		// Create a repo
 		req := github.TemplateRepoRequest{
 			Name:        &skit.Spec.TemplateRepo.Name,
@@ -82,10 +93,12 @@ func (r *StarterKitReconciler) createTargetGitHubRepo(client *github.Client, ski
 		}
 		reqLogger.Info("Repo created successfully", "GitHub URL", *createdRepo.HTMLURL)
 
-		// Set the TargetRepo to the repo created
+		// Set the TargetRepo to the repo created. The parameters are only applied to the repo created here, in the
+		// same status update, so that they are never applied to a repo that was already in use
 		skit.Status.TargetRepo = *createdRepo.HTMLURL
+		skit.Status.ParametersPending = len(skit.Spec.TemplateRepo.Parameters) > 0
 
-		if err := r.Client.Status().Update(ctx, skit); err != nil {
+		if err := r.updateStarterKitStatus(ctx, skit); err != nil {
 			return err
 		}
 
@@ -94,13 +107,19 @@ func (r *StarterKitReconciler) createTargetGitHubRepo(client *github.Client, ski
 	return nil
 }
 
+// webHookSecretKey is the key of the webhook secret in the Secret created for a StarterKit
+const webHookSecretKey = "WebHookSecretKey"
+
+// previousWebHookSecretKey is the key of the webhook secret replaced by the last rotation
+const previousWebHookSecretKey = "PreviousWebHookSecretKey"
+
 // Create a new Secret
 func newSecretForCR(cr *devxv1alpha1.StarterKit, token string) *corev1.Secret {
 	labels := map[string]string{
 		"app": cr.Name,
 	}
 	stringData := map[string]string{
-		"WebHookSecretKey": token,
+		webHookSecretKey: token,
 	}
 
 	return &corev1.Secret{
@@ -126,9 +145,14 @@ func newServiceForCR(cr *devxv1alpha1.StarterKit) *corev1.Service {
 	selector := map[string]string{
 		"name": cr.Name,
 	}
-	port := int32(3000)
-	if cr.Spec.Options.Port > 0 {
-		port = cr.Spec.Options.Port
+	var ports []corev1.ServicePort
+	for _, p := range portsForCR(cr) {
+		ports = append(ports, corev1.ServicePort{
+			Name:       p.Name,
+			Port:       p.Port,
+			Protocol:   p.Protocol,
+			TargetPort: intstr.FromInt(int(p.Port)),
+		})
 	}
 
 	return &corev1.Service{
@@ -142,24 +166,47 @@ func newServiceForCR(cr *devxv1alpha1.StarterKit) *corev1.Service {
 			Labels:    labels,
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{
-				{
-					Name:       "web",
-					Port:       port,
-					TargetPort: intstr.FromInt(int(port)),
-				},
-			},
+			Ports:    ports,
 			Selector: selector,
 		},
 	}
 }
 
-// Create a new Route
-func newRouteForCR(cr *devxv1alpha1.StarterKit) *routev1.Route {
+// Create a new Route. The certificate Secret is only used when TLS termination is configured on the StarterKit.
+func newRouteForCR(cr *devxv1alpha1.StarterKit, certificate *corev1.Secret) *routev1.Route {
 	labels := map[string]string{
 		"app":  cr.Name,
 		"devx": "",
 	}
+	annotations := map[string]string{}
+	for k, v := range cr.Spec.Route.Annotations {
+		annotations[k] = v
+	}
+
+	termination := cr.Spec.Route.Termination
+	if termination == "" && wantsCertificate(cr) {
+		termination = string(routev1.TLSTerminationEdge)
+	}
+
+	var tls *routev1.TLSConfig
+	if termination != "" {
+		tls = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationType(termination),
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+		if cr.Spec.Route.InsecureEdgeTerminationPolicy != "" {
+			tls.InsecureEdgeTerminationPolicy = routev1.InsecureEdgeTerminationPolicyType(cr.Spec.Route.InsecureEdgeTerminationPolicy)
+		}
+		// passthrough routes hand the TLS connection to the pod, so the router never needs the certificate
+		if certificate != nil && tls.Termination != routev1.TLSTerminationPassthrough {
+			tls.Certificate = string(certificate.Data[corev1.TLSCertKey])
+			tls.Key = string(certificate.Data[corev1.TLSPrivateKeyKey])
+			tls.CACertificate = string(certificate.Data["ca.crt"])
+			if tls.Termination == routev1.TLSTerminationReencrypt {
+				tls.DestinationCACertificate = string(certificate.Data["destination-ca.crt"])
+			}
+		}
+	}
 
 	return &routev1.Route{
 		TypeMeta: metav1.TypeMeta{
@@ -167,19 +214,75 @@ func newRouteForCR(cr *devxv1alpha1.StarterKit) *routev1.Route {
 			APIVersion: "github.com/openshift/api/route/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      cr.Name,
-			Namespace: cr.Namespace,
-			Labels:    labels,
+			Annotations: annotations,
+			Name:        cr.Name,
+			Namespace:   cr.Namespace,
+			Labels:      labels,
 		},
 		Spec: routev1.RouteSpec{
+			Host: cr.Spec.Route.Host,
+			Path: cr.Spec.Route.Path,
 			To: routev1.RouteTargetReference{
 				Kind: "Service",
 				Name: cr.Name,
 			},
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString(routePortForCR(cr)),
+			},
+			TLS: tls,
 		},
 	}
 }
 
+// Copies the fields managed by the StarterKit from the desired Route onto the found Route. Returns true if the found
+// Route was changed and needs to be updated. The host is left alone when the StarterKit does not request one, since
+// it is then assigned by the router.
+func mergeRoute(found *routev1.Route, desired *routev1.Route) bool {
+	changed := false
+	if desired.Spec.Host != "" && found.Spec.Host != desired.Spec.Host {
+		found.Spec.Host = desired.Spec.Host
+		changed = true
+	}
+	if found.Spec.Path != desired.Spec.Path {
+		found.Spec.Path = desired.Spec.Path
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(found.Spec.Port, desired.Spec.Port) {
+		found.Spec.Port = desired.Spec.Port
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(found.Spec.TLS, desired.Spec.TLS) {
+		found.Spec.TLS = desired.Spec.TLS
+		changed = true
+	}
+	for k, v := range desired.Annotations {
+		if found.Annotations[k] != v {
+			if found.Annotations == nil {
+				found.Annotations = map[string]string{}
+			}
+			found.Annotations[k] = v
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Returns the URL the specified Route serves the application on.
+func urlForRoute(route *routev1.Route) string {
+	host := route.Spec.Host
+	if host == "" && len(route.Status.Ingress) > 0 {
+		host = route.Status.Ingress[0].Host
+	}
+	if host == "" {
+		return ""
+	}
+	scheme := "http://"
+	if route.Spec.TLS != nil {
+		scheme = "https://"
+	}
+	return scheme + host + route.Spec.Path
+}
+
 // Create a new ImageStream
 func newImageStreamForCR(cr *devxv1alpha1.StarterKit) *imagev1.ImageStream {
 	labels := map[string]string{
@@ -225,12 +328,7 @@ func newBuildForCR(cr *devxv1alpha1.StarterKit) *buildv1.BuildConfig {
 						Ref: "master",
 					},
 				},
-				Strategy: buildv1.BuildStrategy{
-					Type: buildv1.SourceBuildStrategyType,
-					DockerStrategy: &buildv1.DockerBuildStrategy{
-						DockerfilePath: "Dockerfile",
-					},
-				},
+				Strategy: buildStrategyForCR(cr),
 				Output: buildv1.BuildOutput{
 					To: &corev1.ObjectReference{
 						Kind: "ImageStreamTag",
@@ -272,9 +370,13 @@ func newDeploymentForCR(cr *devxv1alpha1.StarterKit) *appsv1.DeploymentConfig {
 	annotations := map[string]string{
 		"app.openshift.io/vcs-uri": cr.Status.TargetRepo,
 	}
-	port := int32(3000)
-	if cr.Spec.Options.Port > 0 {
-		port = cr.Spec.Options.Port
+	var ports []corev1.ContainerPort
+	for _, p := range portsForCR(cr) {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          p.Name,
+			ContainerPort: p.Port,
+			Protocol:      p.Protocol,
+		})
 	}
 	env := cr.Spec.Options.Env
 
@@ -318,14 +420,12 @@ func newDeploymentForCR(cr *devxv1alpha1.StarterKit) *appsv1.DeploymentConfig {
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
 						{
-							Name:  cr.Name,
-							Image: cr.Name,
-							Ports: []corev1.ContainerPort{
-								{
-									ContainerPort: int32(port),
-								},
-							},
-							Env: env,
+							Name:           cr.Name,
+							Image:          cr.Name,
+							Ports:          ports,
+							Env:            env,
+							LivenessProbe:  cr.Spec.Options.LivenessProbe,
+							ReadinessProbe: cr.Spec.Options.ReadinessProbe,
 						},
 					},
 				},
@@ -334,6 +434,47 @@ func newDeploymentForCR(cr *devxv1alpha1.StarterKit) *appsv1.DeploymentConfig {
 	}
 }
 
+// Returns the ports exposed by the application defined in the specified StarterKit. A single "web" port is derived
+// from Options.Port when no named ports are listed.
+func portsForCR(cr *devxv1alpha1.StarterKit) []devxv1alpha1.StarterKitSpecPort {
+	var ports []devxv1alpha1.StarterKitSpecPort
+	if len(cr.Spec.Options.Ports) > 0 {
+		ports = append(ports, cr.Spec.Options.Ports...)
+	} else {
+		port := int32(3000)
+		if cr.Spec.Options.Port > 0 {
+			port = cr.Spec.Options.Port
+		}
+		ports = append(ports, devxv1alpha1.StarterKitSpecPort{Name: "web", Port: port})
+	}
+	for i := range ports {
+		if ports[i].Protocol == "" {
+			ports[i].Protocol = corev1.ProtocolTCP
+		}
+	}
+	return ports
+}
+
+// Returns the name of the port targeted by the application Route.
+func routePortForCR(cr *devxv1alpha1.StarterKit) string {
+	if cr.Spec.Options.RoutePort != "" {
+		return cr.Spec.Options.RoutePort
+	}
+	return portsForCR(cr)[0].Name
+}
+
+// Returns the port targeted by the application Route, or an error if the route port of the specified StarterKit does
+// not name any of its ports.
+func routeTargetPort(cr *devxv1alpha1.StarterKit) (devxv1alpha1.StarterKitSpecPort, error) {
+	routePort := routePortForCR(cr)
+	for _, p := range portsForCR(cr) {
+		if p.Name == routePort {
+			return p, nil
+		}
+	}
+	return devxv1alpha1.StarterKitSpecPort{}, fmt.Errorf("route port %q does not match any port of the application", routePort)
+}
+
 // ========================================================================
 // UI resources
 
@@ -483,6 +624,82 @@ func NewRouteForUI(namespace string) *routev1.Route {
 	}
 }
 
+// ========================================================================
+// GitHub webhook receiver resources
+
+// WebhookReceiverName is the name of the GitHub webhook receiver resources
+const WebhookReceiverName = "starter-kit-operator-github-webhook"
+
+// WebhookReceiverPort is the port the GitHub webhook receiver listens on
+const WebhookReceiverPort = int32(8082)
+
+// NewServiceForWebhookReceiver returns a new Service for the GitHub webhook receiver served by the operator pods
+func NewServiceForWebhookReceiver(namespace string) *corev1.Service {
+	labels := map[string]string{
+		"app":  WebhookReceiverName,
+		"devx": "",
+	}
+	selector := map[string]string{
+		"control-plane": "starter-kit-operator",
+	}
+
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "k8s.io/api/core/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      WebhookReceiverName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "github-webhook",
+					Port:       WebhookReceiverPort,
+					TargetPort: intstr.FromInt(int(WebhookReceiverPort)),
+				},
+			},
+			Selector: selector,
+		},
+	}
+}
+
+// NewRouteForWebhookReceiver returns a new Route exposing the GitHub webhook receiver to GitHub
+func NewRouteForWebhookReceiver(namespace string) *routev1.Route {
+	labels := map[string]string{
+		"app":  WebhookReceiverName,
+		"devx": "",
+	}
+
+	return &routev1.Route{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Route",
+			APIVersion: "github.com/openshift/api/route/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      WebhookReceiverName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: routev1.RouteSpec{
+			Path: GitHubWebhookPath,
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: WebhookReceiverName,
+			},
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromInt(int(WebhookReceiverPort)),
+			},
+			TLS: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationEdge,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			},
+		},
+	}
+}
+
 // NewConsoleLinkForUI returns a new ConsoleLink for the starter kit operator UI
 func NewConsoleLinkForUI(namespace string, href string) *consolev1.ConsoleLink {
 	labels := map[string]string{
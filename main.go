@@ -19,9 +19,15 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+	"golang.org/x/oauth2"
 
 	appsv1 "github.com/openshift/api/apps/v1"
 	buildv1 "github.com/openshift/api/build/v1"
@@ -35,6 +41,8 @@ import (
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
 	consolev1client "github.com/openshift/client-go/console/clientset/versioned/typed/console/v1"
 	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 
@@ -78,8 +86,11 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var githubWebhookAddr string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&githubWebhookAddr, "github-webhook-bind-address", fmt.Sprintf(":%d", controllers.WebhookReceiverPort),
+		"The address the GitHub webhook receiver binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -112,16 +123,73 @@ func main() {
 		os.Exit(1)
 	}
 
+	buildClient := buildv1client.NewForConfigOrDie(mgr.GetConfig())
 	if err = (&controllers.StarterKitReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("StarterKit"),
-		Scheme: mgr.GetScheme(),
+		Client:            mgr.GetClient(),
+		Log:               ctrl.Log.WithName("controllers").WithName("StarterKit"),
+		Scheme:            mgr.GetScheme(),
+		OperatorNamespace: namespace,
+		BuildClient:       buildClient,
+		AppsClient:        appsv1client.NewForConfigOrDie(mgr.GetConfig()),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "StarterKit")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&devxv1alpha1.StarterKit{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "StarterKit")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if err = mgr.Add(&controllers.GitHubWebhookReceiver{
+		Client:      mgr.GetClient(),
+		BuildClient: buildClient,
+		Log:         ctrl.Log.WithName("webhooks").WithName("GitHub"),
+		BindAddress: githubWebhookAddr,
+	}); err != nil {
+		setupLog.Error(err, "unable to create GitHub webhook receiver")
+		os.Exit(1)
+	}
+
+	// ========================================================================
+	if orgs, ok := os.LookupEnv("SKIT_CATALOG_ORGS"); ok && orgs != "" {
+		topics := controllers.DefaultCatalogTopic
+		if topicsVar, ok := os.LookupEnv("SKIT_CATALOG_TOPICS"); ok && topicsVar != "" {
+			topics = topicsVar
+		}
+		interval := controllers.DefaultCatalogSyncInterval
+		if intervalVar, ok := os.LookupEnv("SKIT_CATALOG_SYNC_INTERVAL"); ok && intervalVar != "" {
+			if interval, err = time.ParseDuration(intervalVar); err != nil {
+				setupLog.Error(err, "Invalid SKIT_CATALOG_SYNC_INTERVAL")
+				os.Exit(1)
+			}
+			if interval <= 0 {
+				setupLog.Error(fmt.Errorf("interval must be positive, got %s", interval), "Invalid SKIT_CATALOG_SYNC_INTERVAL")
+				os.Exit(1)
+			}
+		}
+		httpClient := http.DefaultClient
+		if token, ok := os.LookupEnv("SKIT_CATALOG_GITHUB_TOKEN"); ok && token != "" {
+			httpClient = oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+		} else {
+			setupLog.Info("SKIT_CATALOG_GITHUB_TOKEN is not set, the catalog is synced with the unauthenticated GitHub rate limit")
+		}
+		setupLog.Info("Syncing StarterKitTemplate catalog", "orgs", orgs, "topics", topics, "interval", interval)
+		if err := mgr.Add(&controllers.CatalogSyncer{
+			Client:       mgr.GetClient(),
+			GitHubClient: github.NewClient(httpClient),
+			Log:          ctrl.Log.WithName("catalog"),
+			Orgs:         strings.Split(orgs, ","),
+			Topics:       strings.Split(topics, ","),
+			Interval:     interval,
+		}); err != nil {
+			setupLog.Error(err, "unable to create catalog syncer")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -256,6 +324,62 @@ func main() {
 		}
 	}
 
+	// ========================================================================
+	setupLog.Info("Installing GitHub webhook receiver resources")
+	{
+		coreclient := kubernetes.NewForConfigOrDie(mgr.GetConfig())
+		routev1client := routev1client.NewForConfigOrDie(mgr.GetConfig())
+		// Set operator deployment instance as the owner and controller of all resources so that they get deleted when the operator is uninstalled
+		operatorDeployment, err := coreclient.AppsV1().Deployments(namespace).Get(context.TODO(), "starter-kit-operator", metav1.GetOptions{})
+		if err != nil && errors.IsNotFound(err) {
+			setupLog.Error(err, "Could not find Operator Deployment")
+		}
+
+		// service
+		foundService, err := coreclient.CoreV1().Services(namespace).Get(context.TODO(), controllers.WebhookReceiverName, metav1.GetOptions{})
+		if err != nil && errors.IsNotFound(err) {
+			setupLog.Info("Creating a new Service for the GitHub webhook receiver", "Namespace", namespace, "Name", controllers.WebhookReceiverName)
+			webhookService := controllers.NewServiceForWebhookReceiver(namespace)
+			if err := controllerutil.SetControllerReference(operatorDeployment, webhookService, mgr.GetScheme()); err != nil {
+				setupLog.Error(err, "Error setting Operator Deployment as owner of GitHub webhook receiver Service")
+			}
+			_, err = coreclient.CoreV1().Services(namespace).Create(context.TODO(), webhookService, metav1.CreateOptions{})
+			if err != nil {
+				setupLog.Error(err, "Error creating Service for the GitHub webhook receiver")
+			} else {
+				// Service created successfully
+				setupLog.Info("Service for the GitHub webhook receiver created successfully")
+			}
+		} else if err != nil {
+			setupLog.Error(err, "Error fetching Service for the GitHub webhook receiver")
+		} else {
+			// Service already exists - don't requeue
+			setupLog.Info("Skip reconcile: Service for the GitHub webhook receiver already exists", "Service.Namespace", foundService.Namespace, "Service.Name", foundService.Name)
+		}
+
+		// route
+		foundRoute, err := routev1client.Routes(namespace).Get(context.TODO(), controllers.WebhookReceiverName, metav1.GetOptions{})
+		if err != nil && errors.IsNotFound(err) {
+			setupLog.Info("Creating a new Route for the GitHub webhook receiver", "Namespace", namespace, "Name", controllers.WebhookReceiverName)
+			webhookRoute := controllers.NewRouteForWebhookReceiver(namespace)
+			if err := controllerutil.SetControllerReference(operatorDeployment, webhookRoute, mgr.GetScheme()); err != nil {
+				setupLog.Error(err, "Error setting Operator Deployment as owner of GitHub webhook receiver Route")
+			}
+			_, err = routev1client.Routes(namespace).Create(context.TODO(), webhookRoute, metav1.CreateOptions{})
+			if err != nil {
+				setupLog.Error(err, "Error creating Route for the GitHub webhook receiver")
+			} else {
+				// Route created successfully
+				setupLog.Info("Route for the GitHub webhook receiver created successfully")
+			}
+		} else if err != nil {
+			setupLog.Error(err, "Error fetching Route for the GitHub webhook receiver")
+		} else {
+			// Route already exists - don't requeue
+			setupLog.Info("Skip reconcile: Route for the GitHub webhook receiver already exists", "Route.Namespace", foundRoute.Namespace, "Route.Name", foundRoute.Name)
+		}
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")